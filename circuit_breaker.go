@@ -0,0 +1,308 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConsecutiveFailures = 5
+	defaultFailureRateRequests = 20
+	defaultFailureRateWindow   = 10 * time.Second
+	defaultOpenTimeout         = 30 * time.Second
+	defaultHalfOpenRequests    = 1
+)
+
+// errRequestNotSent is recorded for the requests that fail on the client
+// side before they are sent, which are not counted by the circuit breaker.
+var errRequestNotSent = errors.New("request not sent")
+
+// CircuitState is the state of the circuit breaker of an index.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all the requests through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails the requests fast with a *CircuitOpenError,
+	// without sending them.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of probe requests through,
+	// to decide whether the circuit should be closed or opened again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitOpenError is returned for the requests that are
+// not sent because the circuit breaker is open.
+type CircuitOpenError struct {
+	// Time until the circuit breaker lets the probe requests through.
+	// It is zero when the probe requests are already in flight.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("circuit breaker is open, retry after %s", e.RetryAfter)
+	}
+	return "circuit breaker is open"
+}
+
+// CircuitBreakerOptions configures the circuit breaker of the requests.
+//
+// The circuit breaker opens after consecutive failures, or when the rate of
+// the failures in a window exceeds a threshold. While it is open, the requests
+// fail fast with a *CircuitOpenError. After OpenTimeout, it lets probe requests
+// through, and closes again if they succeed.
+//
+// The requests that fail to be sent, the responses with 5xx status codes,
+// and the responses whose bodies fail to be read or report a server error
+// are counted as failures, once the bodies are closed. The responses with
+// 4xx status codes are not, as they are caused by the requests rather than
+// the index, and neither are the requests that fail on the client before
+// they are sent, such as the rate limited ones.
+type CircuitBreakerOptions struct {
+	// Number of the consecutive failures that opens the circuit.
+	// If neither this nor FailureRate is provided, defaults to 5.
+	ConsecutiveFailures int
+
+	// Rate of the failures among the requests of a window, between
+	// 0 and 1, that opens the circuit.
+	// If not provided, the failure rate is not checked.
+	FailureRate float64
+
+	// Minimum number of requests in a window for the failure rate to be checked.
+	// If not provided, defaults to 20.
+	FailureRateRequests int
+
+	// Duration of the windows in which the failure rate is calculated.
+	// If not provided, defaults to 10 seconds.
+	FailureRateWindow time.Duration
+
+	// Duration the circuit stays open before the probe requests are sent.
+	// If not provided, defaults to 30 seconds.
+	OpenTimeout time.Duration
+
+	// Number of the probe requests that should succeed in the half-open
+	// state to close the circuit. Only that many requests are sent at once
+	// in the half-open state, and a single failure opens the circuit again.
+	// If not provided, defaults to 1.
+	HalfOpenRequests int
+}
+
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+
+	mu    sync.Mutex
+	state CircuitState
+
+	// generation is incremented on each state change, so that the results of
+	// the requests sent in a previous state are not counted.
+	generation uint64
+
+	consecutiveFailures int
+	windowStart         time.Time
+	windowRequests      int
+	windowFailures      int
+
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+func newCircuitBreaker(options CircuitBreakerOptions) *circuitBreaker {
+	if options.ConsecutiveFailures <= 0 && options.FailureRate <= 0 {
+		options.ConsecutiveFailures = defaultConsecutiveFailures
+	}
+	if options.FailureRateRequests <= 0 {
+		options.FailureRateRequests = defaultFailureRateRequests
+	}
+	if options.FailureRateWindow <= 0 {
+		options.FailureRateWindow = defaultFailureRateWindow
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = defaultOpenTimeout
+	}
+	if options.HalfOpenRequests <= 0 {
+		options.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	return &circuitBreaker{
+		options:     options,
+		windowStart: time.Now(),
+	}
+}
+
+// CircuitState returns the state of the circuit breaker of the index, to be
+// used in the health checks. It is always CircuitClosed if the circuit breaker
+// is not enabled.
+func (ix *Index) CircuitState() CircuitState {
+	if ix.circuitBreaker == nil {
+		return CircuitClosed
+	}
+	return ix.circuitBreaker.currentState()
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.options.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request can be sent, and returns the function
+// to call with the result of the request if so.
+func (b *circuitBreaker) allow() (done func(err error, statusCode int), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == CircuitOpen {
+		if wait := b.options.OpenTimeout - now.Sub(b.openedAt); wait > 0 {
+			return nil, &CircuitOpenError{RetryAfter: wait}
+		}
+		b.transition(CircuitHalfOpen)
+	}
+
+	probe := b.state == CircuitHalfOpen
+	if probe {
+		if b.probes >= b.options.HalfOpenRequests {
+			return nil, &CircuitOpenError{}
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	var once sync.Once
+	return func(err error, statusCode int) {
+		once.Do(func() {
+			b.record(generation, probe, err, statusCode)
+		})
+	}, nil
+}
+
+func (b *circuitBreaker) record(generation uint64, probe bool, err error, statusCode int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	// The requests that are not sent, such as the rate limited
+	// ones, and the canceled hedged requests are not counted.
+	if errors.Is(err, errRequestNotSent) || errors.Is(err, context.Canceled) {
+		if probe {
+			b.probes--
+		}
+		return
+	}
+
+	failed := err != nil || statusCode >= 500
+	if probe {
+		if failed {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.options.HalfOpenRequests {
+			b.transition(CircuitClosed)
+		}
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(b.windowStart) >= b.options.FailureRateWindow {
+		b.windowStart, b.windowRequests, b.windowFailures = now, 0, 0
+	}
+	b.windowRequests++
+	if !failed {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	b.windowFailures++
+
+	if b.options.ConsecutiveFailures > 0 && b.consecutiveFailures >= b.options.ConsecutiveFailures {
+		b.open()
+		return
+	}
+	if b.options.FailureRate > 0 && b.windowRequests >= b.options.FailureRateRequests &&
+		float64(b.windowFailures) >= b.options.FailureRate*float64(b.windowRequests) {
+		b.open()
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.transition(CircuitOpen)
+	b.openedAt = time.Now()
+}
+
+func (b *circuitBreaker) transition(state CircuitState) {
+	b.state = state
+	b.generation++
+	b.probes, b.successes = 0, 0
+	b.consecutiveFailures = 0
+	b.windowStart, b.windowRequests, b.windowFailures = time.Now(), 0, 0
+}
+
+// breakerBody is a response body that records the result of the request for
+// the circuit breaker when it is closed, so that the errors while reading the
+// body, and the errors in the bodies of the successful responses are counted.
+type breakerBody struct {
+	io.ReadCloser
+	statusCode int
+	done       func(err error, statusCode int)
+	body       []byte
+	err        error
+}
+
+func (b *breakerBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	// The bodies of the errors are small, so the larger
+	// bodies are not kept after their beginning.
+	if len(b.body) <= maxErrorBodySize {
+		b.body = append(b.body, p[:min(n, maxErrorBodySize+1-len(b.body))]...)
+	}
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return
+}
+
+func (b *breakerBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	statusCode, failure := b.statusCode, b.err
+	if failure == nil && len(b.body) <= maxErrorBodySize && bytes.Contains(b.body, []byte(`"error"`)) {
+		var result response[json.RawMessage]
+		if json.Unmarshal(b.body, &result) == nil && result.Error != "" {
+			if result.Status > 0 {
+				statusCode = result.Status
+			} else {
+				failure = errors.New(result.Error)
+			}
+		}
+	}
+	b.done(failure, statusCode)
+	return err
+}
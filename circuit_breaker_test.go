@@ -0,0 +1,173 @@
+package vector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"Service Unavailable","status":503}`))
+			return
+		}
+		if r.URL.Path == deletePath {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Invalid id","status":400}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	t.Run("consecutive failures", func(t *testing.T) {
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			CircuitBreaker: &CircuitBreakerOptions{
+				ConsecutiveFailures: 3,
+				OpenTimeout:         50 * time.Millisecond,
+				HalfOpenRequests:    2,
+			},
+		})
+		require.Equal(t, CircuitClosed, index.CircuitState())
+
+		// Client errors are not counted as failures.
+		for i := 0; i < 5; i++ {
+			_, err := index.Delete("a")
+			require.EqualError(t, err, "Invalid id")
+		}
+		require.Equal(t, CircuitClosed, index.CircuitState())
+
+		failing.Store(true)
+		for i := 0; i < 3; i++ {
+			_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+			require.EqualError(t, err, "Service Unavailable")
+		}
+		require.Equal(t, CircuitOpen, index.CircuitState())
+
+		sent := requests.Load()
+		_, err := index.Namespace("ns").Fetch(Fetch{Ids: []string{"a"}})
+		var openErr *CircuitOpenError
+		require.True(t, errors.As(err, &openErr))
+		require.Greater(t, openErr.RetryAfter, time.Duration(0))
+		require.Equal(t, sent, requests.Load())
+
+		// A failed probe opens the circuit again.
+		time.Sleep(60 * time.Millisecond)
+		require.Equal(t, CircuitHalfOpen, index.CircuitState())
+		_, err = index.Fetch(Fetch{Ids: []string{"a"}})
+		require.EqualError(t, err, "Service Unavailable")
+		require.Equal(t, CircuitOpen, index.CircuitState())
+
+		failing.Store(false)
+		time.Sleep(60 * time.Millisecond)
+		_, err = index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Equal(t, CircuitHalfOpen, index.CircuitState())
+		_, err = index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Equal(t, CircuitClosed, index.CircuitState())
+	})
+
+	t.Run("failure rate", func(t *testing.T) {
+		failing.Store(false)
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			CircuitBreaker: &CircuitBreakerOptions{
+				FailureRate:         0.5,
+				FailureRateRequests: 4,
+			},
+		})
+
+		for i := 0; i < 3; i++ {
+			failing.Store(i%2 == 1)
+			_, _ = index.Fetch(Fetch{Ids: []string{"a"}})
+		}
+		require.Equal(t, CircuitClosed, index.CircuitState())
+
+		failing.Store(true)
+		_, _ = index.Fetch(Fetch{Ids: []string{"a"}})
+		require.Equal(t, CircuitOpen, index.CircuitState())
+	})
+}
+
+// failingCodec is a Codec that fails to compress the request bodies.
+type failingCodec struct {
+	gzipCodec
+}
+
+func (failingCodec) Compress(dst []byte, body []byte) ([]byte, error) {
+	return nil, errors.New("compression failed")
+}
+
+func TestCircuitBreakerBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fetchPath:
+			// The errors in the bodies of the successful responses are failures.
+			_, _ = w.Write([]byte(`{"error":"Internal error","status":500}`))
+		case queryPath:
+			// The response is cut before its body is read completely.
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte(`{"result":[`))
+		default:
+			_, _ = w.Write([]byte(`{"result":"Success"}`))
+		}
+	}))
+	defer server.Close()
+
+	newIndex := func() *Index {
+		return NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			CircuitBreaker: &CircuitBreakerOptions{
+				ConsecutiveFailures: 2,
+			},
+			Compression: &CompressionOptions{
+				Codec:     failingCodec{},
+				Threshold: 100,
+			},
+		})
+	}
+
+	t.Run("error in body", func(t *testing.T) {
+		index := newIndex()
+		for i := 0; i < 2; i++ {
+			_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+			require.EqualError(t, err, "Internal error")
+		}
+		require.Equal(t, CircuitOpen, index.CircuitState())
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		index := newIndex()
+		for i := 0; i < 2; i++ {
+			_, err := index.Query(Query{Vector: []float32{0.6, 0.8}})
+			require.Error(t, err)
+		}
+		require.Equal(t, CircuitOpen, index.CircuitState())
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		// The requests that fail before they are sent are not counted.
+		index := newIndex()
+		for i := 0; i < 3; i++ {
+			err := index.Upsert(Upsert{Id: "a", Data: strings.Repeat("a", 100)})
+			require.EqualError(t, err, "compression failed")
+		}
+		require.Equal(t, CircuitClosed, index.CircuitState())
+	})
+}
@@ -0,0 +1,56 @@
+package vector
+
+import "slices"
+
+// cloneVectorScores returns a deep copy of the scores, so that
+// the copy can be read while the original is modified.
+func cloneVectorScores(scores []VectorScore) []VectorScore {
+	if scores == nil {
+		return nil
+	}
+	cloned := make([]VectorScore, len(scores))
+	for i, score := range scores {
+		score.Vector = slices.Clone(score.Vector)
+		score.SparseVector = cloneSparseVector(score.SparseVector)
+		score.Metadata = cloneMetadata(score.Metadata)
+		cloned[i] = score
+	}
+	return cloned
+}
+
+func cloneSparseVector(v *SparseVector) *SparseVector {
+	if v == nil {
+		return nil
+	}
+	return &SparseVector{
+		Indices: slices.Clone(v.Indices),
+		Values:  slices.Clone(v.Values),
+	}
+}
+
+func cloneMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	return cloneValue(metadata).(map[string]any)
+}
+
+// cloneValue returns a deep copy of the maps and slices
+// of a value decoded from JSON.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		cloned := make(map[string]any, len(v))
+		for key, value := range v {
+			cloned[key] = cloneValue(value)
+		}
+		return cloned
+	case []any:
+		cloned := make([]any, len(v))
+		for i, value := range v {
+			cloned[i] = cloneValue(value)
+		}
+		return cloned
+	}
+	return v
+}
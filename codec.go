@@ -0,0 +1,657 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// The codec below encodes upserts and decodes the vectors and scores in the
+// responses without going through the reflection based encoding/json, which
+// dominates the cost of the requests with high dimensional vectors. Its output
+// is equivalent to the output of encoding/json. Metadata and the other rarely
+// used values are still handled with encoding/json.
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 64*1024)
+		return &b
+	},
+}
+
+// maxPooledBufferSize is the capacity above which the buffers are not
+// put back into the pool, so that a single large request does not
+// keep a large buffer alive.
+const maxPooledBufferSize = 16 * 1024 * 1024
+
+func getBuffer() *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) <= maxPooledBufferSize {
+		bufferPool.Put(b)
+	}
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) appendUpserts(u []Upsert) error {
+	e.buf = append(e.buf, '[')
+	for i := range u {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if err := e.appendUpsert(&u[i]); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+func (e *encoder) appendUpsert(u *Upsert) error {
+	e.buf = append(e.buf, `{"id":`...)
+	e.appendString(u.Id)
+
+	if len(u.Vector) > 0 {
+		e.buf = append(e.buf, `,"vector":`...)
+		if err := e.appendFloats(u.Vector); err != nil {
+			return err
+		}
+	}
+
+	if u.SparseVector != nil {
+		e.buf = append(e.buf, `,"sparseVector":`...)
+		if err := e.appendSparseVector(u.SparseVector); err != nil {
+			return err
+		}
+	}
+
+	if u.Data != "" {
+		e.buf = append(e.buf, `,"data":`...)
+		e.appendString(u.Data)
+	}
+
+	if len(u.Metadata) > 0 {
+		metadata, err := json.Marshal(u.Metadata)
+		if err != nil {
+			return err
+		}
+		e.buf = append(e.buf, `,"metadata":`...)
+		e.buf = append(e.buf, metadata...)
+	}
+
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+func (e *encoder) appendSparseVector(sv *SparseVector) error {
+	e.buf = append(e.buf, `{"indices":`...)
+	if sv.Indices == nil {
+		e.buf = append(e.buf, "null"...)
+	} else {
+		e.buf = append(e.buf, '[')
+		for i, index := range sv.Indices {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = strconv.AppendInt(e.buf, int64(index), 10)
+		}
+		e.buf = append(e.buf, ']')
+	}
+
+	e.buf = append(e.buf, `,"values":`...)
+	if err := e.appendFloats(sv.Values); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+func (e *encoder) appendFloats(values []float32) error {
+	if values == nil {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+
+	e.buf = append(e.buf, '[')
+	for i, f := range values {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		var err error
+		if e.buf, err = appendFloat32(e.buf, f); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// appendString appends the string as it would be encoded by encoding/json.
+// Strings consisting of the characters that need no escaping are appended
+// as they are, and the rest are encoded with encoding/json.
+func (e *encoder) appendString(s string) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			encoded, _ := json.Marshal(s)
+			e.buf = append(e.buf, encoded...)
+			return
+		}
+	}
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, '"')
+}
+
+// appendFloat32 appends the value in the same format encoding/json uses.
+func appendFloat32(b []byte, f float32) ([]byte, error) {
+	f64 := float64(f)
+	if math.IsNaN(f64) || math.IsInf(f64, 0) {
+		return b, fmt.Errorf("unsupported float value: %v", f)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f64); abs != 0 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+		format = 'e'
+	}
+
+	b = strconv.AppendFloat(b, f64, format, -1, 32)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of JSON input")
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte without consuming it.
+func (d *decoder) peek() (byte, error) {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return 0, errUnexpectedEnd
+	}
+	return d.data[d.pos], nil
+}
+
+func (d *decoder) expect(c byte) error {
+	next, err := d.peek()
+	if err != nil {
+		return err
+	}
+	if next != c {
+		return d.errorf("expected %q, found %q", c, next)
+	}
+	d.pos++
+	return nil
+}
+
+// null consumes a null literal and reports whether it was found.
+func (d *decoder) null() bool {
+	if c, err := d.peek(); err != nil || c != 'n' {
+		return false
+	}
+	if len(d.data)-d.pos >= 4 && string(d.data[d.pos:d.pos+4]) == "null" {
+		d.pos += 4
+		return true
+	}
+	return false
+}
+
+// object decodes an object, calling field for each key with the decoder
+// positioned at the value. A null value is treated as an empty object.
+func (d *decoder) object(field func(key []byte) error) error {
+	if d.null() {
+		return nil
+	}
+	if err := d.expect('{'); err != nil {
+		return err
+	}
+	if c, err := d.peek(); err != nil {
+		return err
+	} else if c == '}' {
+		d.pos++
+		return nil
+	}
+
+	for {
+		key, err := d.stringBytes()
+		if err != nil {
+			return err
+		}
+		if err = d.expect(':'); err != nil {
+			return err
+		}
+		if err = field(key); err != nil {
+			return err
+		}
+
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		d.pos++
+		switch c {
+		case ',':
+		case '}':
+			return nil
+		default:
+			d.pos--
+			return d.errorf("expected ',' or '}', found %q", c)
+		}
+	}
+}
+
+// array decodes an array, calling elem for each element with the decoder
+// positioned at the element. A null value is treated as an empty array.
+func (d *decoder) array(elem func() error) error {
+	if d.null() {
+		return nil
+	}
+	if err := d.expect('['); err != nil {
+		return err
+	}
+	if c, err := d.peek(); err != nil {
+		return err
+	} else if c == ']' {
+		d.pos++
+		return nil
+	}
+
+	for {
+		if err := elem(); err != nil {
+			return err
+		}
+
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		d.pos++
+		switch c {
+		case ',':
+		case ']':
+			return nil
+		default:
+			d.pos--
+			return d.errorf("expected ',' or ']', found %q", c)
+		}
+	}
+}
+
+// stringBytes decodes a string. The returned bytes alias the
+// input unless the string contains escape sequences.
+func (d *decoder) stringBytes() ([]byte, error) {
+	if err := d.expect('"'); err != nil {
+		return nil, err
+	}
+
+	start := d.pos
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case '"':
+			d.pos++
+			return d.data[start : d.pos-1], nil
+		case '\\':
+			return d.escapedString(start - 1)
+		}
+		d.pos++
+	}
+	return nil, errUnexpectedEnd
+}
+
+// escapedString decodes the string starting at the given offset
+// with encoding/json, as it contains escape sequences.
+func (d *decoder) escapedString(start int) ([]byte, error) {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case '\\':
+			d.pos += 2
+			continue
+		case '"':
+			d.pos++
+			var s string
+			if err := json.Unmarshal(d.data[start:d.pos], &s); err != nil {
+				return nil, err
+			}
+			return []byte(s), nil
+		}
+		d.pos++
+	}
+	return nil, errUnexpectedEnd
+}
+
+func (d *decoder) string() (string, error) {
+	if d.null() {
+		return "", nil
+	}
+	b, err := d.stringBytes()
+	return string(b), err
+}
+
+func (d *decoder) number() ([]byte, error) {
+	d.skipSpace()
+	start := d.pos
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; {
+		case c >= '0' && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+			d.pos++
+			continue
+		}
+		break
+	}
+	if d.pos == start {
+		if d.pos >= len(d.data) {
+			return nil, errUnexpectedEnd
+		}
+		return nil, d.errorf("expected number, found %q", d.data[d.pos])
+	}
+	return d.data[start:d.pos], nil
+}
+
+func (d *decoder) float32() (float32, error) {
+	if d.null() {
+		return 0, nil
+	}
+	n, err := d.number()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(string(n), 32)
+	return float32(f), err
+}
+
+func (d *decoder) int() (int64, error) {
+	if d.null() {
+		return 0, nil
+	}
+	n, err := d.number()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// floats decodes an array of numbers.
+func (d *decoder) floats() ([]float32, error) {
+	if d.null() {
+		return nil, nil
+	}
+
+	values := []float32{}
+	err := d.array(func() error {
+		f, err := d.float32()
+		values = append(values, f)
+		return err
+	})
+	return values, err
+}
+
+func (d *decoder) sparseVector() (*SparseVector, error) {
+	if d.null() {
+		return nil, nil
+	}
+
+	sv := &SparseVector{}
+	err := d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "indices":
+			if d.null() {
+				return nil
+			}
+			sv.Indices = []int32{}
+			return d.array(func() error {
+				index, err := d.int()
+				sv.Indices = append(sv.Indices, int32(index))
+				return err
+			})
+		case "values":
+			sv.Values, err = d.floats()
+			return
+		default:
+			return d.skip()
+		}
+	})
+	return sv, err
+}
+
+func (d *decoder) metadata() (metadata map[string]any, err error) {
+	start := d.pos
+	if err = d.skip(); err != nil {
+		return
+	}
+	err = json.Unmarshal(d.data[start:d.pos], &metadata)
+	return
+}
+
+func (d *decoder) vector(v *Vector) error {
+	return d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "id":
+			v.Id, err = d.string()
+		case "vector":
+			v.Vector, err = d.floats()
+		case "sparseVector":
+			v.SparseVector, err = d.sparseVector()
+		case "metadata":
+			v.Metadata, err = d.metadata()
+		case "data":
+			v.Data, err = d.string()
+		default:
+			err = d.skip()
+		}
+		return
+	})
+}
+
+func (d *decoder) vectors() ([]Vector, error) {
+	if d.null() {
+		return nil, nil
+	}
+	vectors := []Vector{}
+	err := d.array(func() error {
+		vectors = append(vectors, Vector{})
+		return d.vector(&vectors[len(vectors)-1])
+	})
+	return vectors, err
+}
+
+func (d *decoder) vectorScore(s *VectorScore) error {
+	return d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "id":
+			s.Id, err = d.string()
+		case "score":
+			s.Score, err = d.float32()
+		case "vector":
+			s.Vector, err = d.floats()
+		case "sparseVector":
+			s.SparseVector, err = d.sparseVector()
+		case "metadata":
+			s.Metadata, err = d.metadata()
+		case "data":
+			s.Data, err = d.string()
+		case "namespace":
+			s.Namespace, err = d.string()
+		default:
+			err = d.skip()
+		}
+		return
+	})
+}
+
+func (d *decoder) vectorScores() ([]VectorScore, error) {
+	if d.null() {
+		return nil, nil
+	}
+	scores := []VectorScore{}
+	err := d.array(func() error {
+		scores = append(scores, VectorScore{})
+		return d.vectorScore(&scores[len(scores)-1])
+	})
+	return scores, err
+}
+
+func (d *decoder) rangeVectors() (r RangeVectors, err error) {
+	err = d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "nextCursor":
+			r.NextCursor, err = d.string()
+		case "vectors":
+			r.Vectors, err = d.vectors()
+		default:
+			err = d.skip()
+		}
+		return
+	})
+	return
+}
+
+func (d *decoder) resumableQueryStart() (s resumableQueryStart, err error) {
+	err = d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "uuid":
+			s.UUID, err = d.string()
+		case "scores":
+			s.Scores, err = d.vectorScores()
+		default:
+			err = d.skip()
+		}
+		return
+	})
+	return
+}
+
+// skip skips the next value.
+func (d *decoder) skip() error {
+	c, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c == '{':
+		return d.object(func([]byte) error {
+			return d.skip()
+		})
+	case c == '[':
+		return d.array(d.skip)
+	case c == '"':
+		_, err = d.stringBytes()
+		return err
+	case c == '-' || (c >= '0' && c <= '9'):
+		_, err = d.number()
+		return err
+	}
+
+	for _, literal := range []string{"null", "true", "false"} {
+		if len(d.data)-d.pos >= len(literal) && string(d.data[d.pos:d.pos+len(literal)]) == literal {
+			d.pos += len(literal)
+			return nil
+		}
+	}
+	return d.errorf("unexpected character %q", c)
+}
+
+// decodeResponse decodes the response envelope like parseResponse,
+// decoding the result with the given function.
+func decodeResponse[T any](data []byte, result func(d *decoder) (T, error)) (t T, err error) {
+	d := &decoder{data: data}
+
+	var message string
+	err = d.object(func(key []byte) (err error) {
+		switch string(key) {
+		case "result":
+			t, err = result(d)
+		case "error":
+			message, err = d.string()
+		default:
+			err = d.skip()
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if d.skipSpace(); d.pos != len(d.data) {
+		err = d.errorf("unexpected data after the response")
+		return
+	}
+
+	if message != "" {
+		err = errors.New(message)
+	}
+	return
+}
+
+func parseVectorScores(data []byte) ([]VectorScore, error) {
+	return decodeResponse(data, (*decoder).vectorScores)
+}
+
+func parseVectors(data []byte) ([]Vector, error) {
+	return decodeResponse(data, (*decoder).vectors)
+}
+
+func parseRangeVectors(data []byte) (RangeVectors, error) {
+	return decodeResponse(data, (*decoder).rangeVectors)
+}
+
+func parseResumableQueryStart(data []byte) (resumableQueryStart, error) {
+	return decodeResponse(data, (*decoder).resumableQueryStart)
+}
+
+// sendUpserts encodes and sends the upserts with the codec.
+func (ix *Index) sendUpserts(path string, u []Upsert, single bool) (data []byte, err error) {
+	buf := getBuffer()
+
+	e := encoder{buf: *buf}
+	if single {
+		err = e.appendUpsert(&u[0])
+	} else {
+		err = e.appendUpserts(u)
+	}
+	*buf = e.buf
+	if err != nil {
+		putBuffer(buf)
+		return
+	}
+	r := newRequest(path, e.buf, u)
+	r.release = func() {
+		putBuffer(buf)
+	}
+	return ix.sendRequest(r)
+}
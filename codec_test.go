@@ -0,0 +1,186 @@
+package vector
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeUpserts(t *testing.T) {
+	upserts := []Upsert{
+		{Id: "plain"},
+		{
+			Id:     "dense",
+			Vector: []float32{0, 1, -1.5, 1e-7, 3.4e38, 123456.79, -2.5e-10, 1e21},
+			Data:   "<escaped> & \"quoted\"\n ünicode  ",
+			Metadata: map[string]any{
+				"foo": "bar",
+				"n":   1,
+			},
+		},
+		{Id: "sparse", SparseVector: &SparseVector{Indices: []int32{0, 5}, Values: []float32{0.1, 0.2}}},
+		{Id: "nil sparse", SparseVector: &SparseVector{}},
+		{Id: "empty metadata", Metadata: map[string]any{}},
+	}
+
+	expected, err := json.Marshal(upserts)
+	require.NoError(t, err)
+
+	e := encoder{}
+	require.NoError(t, e.appendUpserts(upserts))
+	require.Equal(t, string(expected), string(e.buf))
+
+	expected, err = json.Marshal(upserts[1])
+	require.NoError(t, err)
+
+	e = encoder{}
+	require.NoError(t, e.appendUpsert(&upserts[1]))
+	require.Equal(t, string(expected), string(e.buf))
+
+	e = encoder{}
+	require.Error(t, e.appendUpsert(&Upsert{Id: "nan", Vector: []float32{float32(math.NaN())}}))
+}
+
+func TestDecodeResponses(t *testing.T) {
+	t.Run("scores", func(t *testing.T) {
+		data := []byte(`{
+			"result": [
+				{"id": "a", "score": 0.9, "vector": [0.1, -2e-3, 1E2], "metadata": {"foo": ["bar", 1, null, true]}, "data": "d\"\\u00fc"},
+				{"id": "b", "score": 0.5, "sparseVector": {"indices": [1, 2], "values": [0.5, 1]}, "unknown": {"x": [1, "]"]}},
+				{"id": "c", "score": 0, "vector": [], "metadata": null},
+				null
+			],
+			"status": 200
+		}`)
+
+		expected, err := parseResponse[[]VectorScore](data)
+		require.NoError(t, err)
+
+		scores, err := parseVectorScores(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, scores)
+	})
+
+	t.Run("vectors", func(t *testing.T) {
+		data := []byte(`{"result":[null,{"id":"a","vector":[1,2],"sparseVector":{"indices":[],"values":[]}}]}`)
+
+		expected, err := parseResponse[[]Vector](data)
+		require.NoError(t, err)
+
+		vectors, err := parseVectors(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, vectors)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		data := []byte(`{"result":{"nextCursor":"12","vectors":[{"id":"a","metadata":{"n":1.5}}]}}`)
+
+		expected, err := parseResponse[RangeVectors](data)
+		require.NoError(t, err)
+
+		vectors, err := parseRangeVectors(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, vectors)
+	})
+
+	t.Run("resumable query", func(t *testing.T) {
+		data := []byte(`{"result":{"uuid":"u","scores":[{"id":"a","score":1}]}}`)
+
+		expected, err := parseResponse[resumableQueryStart](data)
+		require.NoError(t, err)
+
+		start, err := parseResumableQueryStart(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, start)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := parseVectorScores([]byte(`{"error":"Unauthorized: Invalid auth token","status":401}`))
+		require.EqualError(t, err, "Unauthorized: Invalid auth token")
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, data := range []string{
+			``,
+			`Unauthorized`,
+			`{"result":[{"id":"a"}`,
+			`{"result":[{"id":"a" "score":1}]}`,
+			`{"result":[{"id":"a","score":x}]}`,
+			`{"result":[]} {}`,
+		} {
+			_, err := parseVectorScores([]byte(data))
+			require.Error(t, err, data)
+		}
+	})
+}
+
+func BenchmarkEncodeUpserts(b *testing.B) {
+	upserts := benchmarkUpserts()
+
+	b.Run("encoding/json", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(upserts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("codec", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			buf := getBuffer()
+			e := encoder{buf: *buf}
+			if err := e.appendUpserts(upserts); err != nil {
+				b.Fatal(err)
+			}
+			*buf = e.buf
+			putBuffer(buf)
+		}
+	})
+}
+
+func BenchmarkDecodeScores(b *testing.B) {
+	scores := make([]VectorScore, 100)
+	for i, u := range benchmarkUpserts() {
+		scores[i] = VectorScore{Id: u.Id, Score: rand.Float32(), Vector: u.Vector, Metadata: u.Metadata}
+	}
+	data, err := json.Marshal(response[[]VectorScore]{Result: scores})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("encoding/json", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := parseResponse[[]VectorScore](data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("codec", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := parseVectorScores(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchmarkUpserts returns 100 upserts with 1536 dimensional vectors.
+func benchmarkUpserts() []Upsert {
+	upserts := make([]Upsert, 100)
+	for i := range upserts {
+		v := make([]float32, 1536)
+		for j := range v {
+			v[j] = rand.Float32()*2 - 1
+		}
+		upserts[i] = Upsert{
+			Id:       randomString(),
+			Vector:   v,
+			Metadata: map[string]any{"index": i},
+		}
+	}
+	return upserts
+}
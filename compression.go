@@ -0,0 +1,136 @@
+package vector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const defaultCompressionThreshold = 16 * 1024
+
+// Codec compresses the request bodies and decompresses the response bodies
+// with a content encoding. The client compresses with gzip by default, and
+// other encodings, such as the zstd of the zstdvector module, are provided
+// as codecs so that the client does not depend on their implementations.
+type Codec interface {
+	// ContentEncoding returns the name of the encoding, as
+	// used in the Content-Encoding and Accept-Encoding headers.
+	ContentEncoding() string
+
+	// Compress appends the compressed body to dst.
+	Compress(dst []byte, body []byte) ([]byte, error)
+
+	// NewReader returns a reader that decompresses the body.
+	NewReader(body io.Reader) (io.ReadCloser, error)
+}
+
+// CompressionOptions configures the compression of the request bodies.
+//
+// When the compression is enabled, compressed responses are also
+// requested from the server and decompressed transparently.
+type CompressionOptions struct {
+	// Codec used to compress the request bodies.
+	// If not provided, the bodies are compressed with gzip.
+	Codec Codec
+
+	// Minimum size of the request bodies in bytes to compress.
+	// Smaller bodies are sent uncompressed.
+	// If not provided, defaults to 16 KiB.
+	Threshold int
+}
+
+type compressor struct {
+	codec     Codec
+	threshold int
+}
+
+func newCompressor(options CompressionOptions) *compressor {
+	if options.Codec == nil {
+		options.Codec = gzipCodec{}
+	}
+	if options.Threshold <= 0 {
+		options.Threshold = defaultCompressionThreshold
+	}
+	return &compressor{
+		codec:     options.Codec,
+		threshold: options.Threshold,
+	}
+}
+
+// acceptEncoding returns the encodings of the responses
+// accepted by the client, in the order of preference.
+func (c *compressor) acceptEncoding() string {
+	if encoding := c.codec.ContentEncoding(); encoding != gzipEncoding {
+		return encoding + ", " + gzipEncoding
+	}
+	return gzipEncoding
+}
+
+// decompressBody returns a reader that decompresses the response body
+// according to the content encoding of the response.
+func (c *compressor) decompressBody(contentEncoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	var codec Codec
+	switch {
+	case contentEncoding == "" || contentEncoding == "identity":
+		return body, nil
+	case c != nil && contentEncoding == c.codec.ContentEncoding():
+		codec = c.codec
+	case contentEncoding == gzipEncoding:
+		codec = gzipCodec{}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", contentEncoding)
+	}
+
+	r, err := codec.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	return &decompressingBody{Reader: r, decompressor: r, body: body}, nil
+}
+
+const gzipEncoding = "gzip"
+
+// gzipCodec is the Codec of gzip, from the standard library.
+type gzipCodec struct{}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+func (gzipCodec) ContentEncoding() string {
+	return gzipEncoding
+}
+
+func (gzipCodec) Compress(dst []byte, body []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(w)
+
+	w.Reset(buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) NewReader(body io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(body)
+}
+
+type decompressingBody struct {
+	io.Reader
+	decompressor io.Closer
+	body         io.Closer
+}
+
+func (b *decompressingBody) Close() error {
+	b.decompressor.Close()
+	return b.body.Close()
+}
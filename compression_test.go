@@ -0,0 +1,179 @@
+package vector
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deflateCodec is a Codec other than gzip, from the standard library.
+type deflateCodec struct{}
+
+func (deflateCodec) ContentEncoding() string {
+	return "deflate"
+}
+
+func (deflateCodec) Compress(dst []byte, body []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w, err := flate.NewWriter(buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (deflateCodec) NewReader(body io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(body), nil
+}
+
+func TestCompression(t *testing.T) {
+	for _, test := range []struct {
+		codec          Codec
+		acceptEncoding string
+	}{
+		{codec: nil, acceptEncoding: "gzip"},
+		{codec: deflateCodec{}, acceptEncoding: "deflate, gzip"},
+	} {
+		c := newCompressor(CompressionOptions{Codec: test.codec})
+		encoding := c.codec.ContentEncoding()
+		t.Run(encoding, func(t *testing.T) {
+			var contentEncoding string
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentEncoding = r.Header.Get("Content-Encoding")
+				require.Equal(t, test.acceptEncoding, r.Header.Get("Accept-Encoding"))
+
+				decompressed, err := c.decompressBody(contentEncoding, r.Body)
+				require.NoError(t, err)
+				body, err = io.ReadAll(decompressed)
+				require.NoError(t, err)
+
+				response, err := c.codec.Compress(nil, []byte(`{"result":"Success"}`))
+				require.NoError(t, err)
+
+				w.Header().Set("Content-Encoding", encoding)
+				_, err = w.Write(response)
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			index := NewIndexWith(Options{
+				Url:   server.URL,
+				Token: "token",
+				Compression: &CompressionOptions{
+					Codec:     test.codec,
+					Threshold: 100,
+				},
+			})
+
+			err := index.Upsert(Upsert{Id: "small"})
+			require.NoError(t, err)
+			require.Equal(t, "", contentEncoding)
+			require.Equal(t, `{"id":"small"}`, string(body))
+
+			data := strings.Repeat("a", 1000)
+			err = index.Upsert(Upsert{Id: "large", Data: data})
+			require.NoError(t, err)
+			require.Equal(t, encoding, contentEncoding)
+			require.Equal(t, `{"id":"large","data":"`+data+`"}`, string(body))
+		})
+	}
+
+	// The gzip responses are decompressed with any codec, but
+	// the other encodings only with their codecs.
+	gzipped, err := gzipCodec{}.Compress(nil, []byte("body"))
+	require.NoError(t, err)
+	r, err := newCompressor(CompressionOptions{Codec: deflateCodec{}}).
+		decompressBody("gzip", io.NopCloser(bytes.NewReader(gzipped)))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "body", string(decompressed))
+
+	_, err = newCompressor(CompressionOptions{}).decompressBody("deflate", io.NopCloser(strings.NewReader("")))
+	require.Error(t, err)
+}
+
+func TestUpsertWithCompression(t *testing.T) {
+	index := NewIndexWith(Options{
+		Url:   os.Getenv(UrlEnvProperty),
+		Token: os.Getenv(TokenEnvProperty),
+		Compression: &CompressionOptions{
+			Threshold: 1,
+		},
+	})
+
+	upserts := make([]Upsert, 100)
+	for i := range upserts {
+		upserts[i] = Upsert{Id: randomString(), Vector: []float32{0.6, 0.8}}
+	}
+	err := index.UpsertMany(upserts)
+	require.NoError(t, err)
+
+	vectors, err := index.Fetch(Fetch{Ids: []string{upserts[0].Id}})
+	require.NoError(t, err)
+	require.Equal(t, upserts[0].Id, vectors[0].Id)
+}
+
+func TestLogCompressedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"result":"Success"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	index := NewIndexWith(Options{
+		Url:          server.URL,
+		Token:        "token",
+		Logger:       slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogVerbosity: LogBodies,
+		Compression: &CompressionOptions{
+			Threshold: 1,
+		},
+	})
+
+	// The pooled upsert buffers are reused as soon as they are released,
+	// so the logged bodies would be overwritten by the concurrent upserts
+	// if they were released before the requests are logged.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := index.UpsertMany([]Upsert{{Id: fmt.Sprintf("id-%d", i), Vector: []float32{0.6, 0.8}}})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	ids := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] != "sending request" {
+			continue
+		}
+		var upserts []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(record["request_body"].(string)), &upserts))
+		ids[upserts[0]["id"].(string)] = true
+	}
+	require.Equal(t, 50, len(ids))
+}
@@ -0,0 +1,138 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCredentialsRefreshInterval = 10 * time.Second
+
+// CredentialProvider provides the token of the index for the requests.
+//
+// The token is requested for each request, so the tokens can be rotated, or
+// swapped between the read-only and the read-write tokens, without creating
+// the clients again. The requests that are sent concurrently with a rotation
+// use either the old or the new token.
+type CredentialProvider interface {
+	// Token returns the token to send a request with. It can be called
+	// concurrently, and should be cheap, so the providers that load the
+	// tokens from slow sources should cache them.
+	Token() (string, error)
+}
+
+// CredentialFunc is a function that implements CredentialProvider.
+type CredentialFunc func() (string, error)
+
+// Token calls the function.
+func (f CredentialFunc) Token() (string, error) {
+	return f()
+}
+
+// StaticCredentials returns a credential provider that always provides the given token.
+func StaticCredentials(token string) CredentialProvider {
+	return CredentialFunc(func() (string, error) {
+		return token, nil
+	})
+}
+
+// EnvCredentials returns a credential provider that provides the token in
+// the given environment variable, which is read for each request. If the name
+// is empty, the token is read from UPSTASH_VECTOR_REST_TOKEN.
+func EnvCredentials(name string) CredentialProvider {
+	if name == "" {
+		name = TokenEnvProperty
+	}
+	return CredentialFunc(func() (string, error) {
+		token := os.Getenv(name)
+		if token == "" {
+			return "", fmt.Errorf("missing token in environment variable %s", name)
+		}
+		return token, nil
+	})
+}
+
+// FileCredentials returns a credential provider that provides the token in the
+// given file, such as a mounted secret. The file is checked for changes at most
+// once per refresh interval, and read again when it is modified. Leading and
+// trailing whitespace of the token is ignored.
+//
+// If the file cannot be read after a token is read from it, the last token
+// is provided until it can be read again.
+//
+// If the refresh interval is not provided, defaults to 10 seconds.
+func FileCredentials(path string, refreshInterval time.Duration) CredentialProvider {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultCredentialsRefreshInterval
+	}
+	return &fileCredentials{
+		path:            path,
+		refreshInterval: refreshInterval,
+	}
+}
+
+type fileCredentials struct {
+	path            string
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	token     string
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+func (c *fileCredentials) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Sub(c.checkedAt) < c.refreshInterval {
+		return c.token, nil
+	}
+
+	err := c.refresh()
+	if err != nil && c.token == "" {
+		return "", err
+	}
+	c.checkedAt = now
+	return c.token, nil
+}
+
+// refresh reads the token again if the file is modified since it was last read.
+func (c *fileCredentials) refresh() error {
+	stat, err := os.Stat(c.path)
+	if err != nil {
+		return err
+	}
+	if c.token != "" && stat.ModTime().Equal(c.modTime) && stat.Size() == c.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return errors.New("missing token in file " + c.path)
+	}
+	c.token, c.modTime, c.size = token, stat.ModTime(), stat.Size()
+	return nil
+}
+
+// authorization returns the value of the Authorization header
+// with the token provided by the credential provider.
+func (ix *Index) authorization() (string, error) {
+	token, err := ix.credentials.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if token == "" {
+		return "", errors.New("failed to get credentials: empty token")
+	}
+	return "Bearer " + token, nil
+}
@@ -0,0 +1,106 @@
+package vector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentials(t *testing.T) {
+	var authorization atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	t.Run("rotation", func(t *testing.T) {
+		var token atomic.Value
+		token.Store("first")
+		index := NewIndexWith(Options{
+			Url: server.URL,
+			Credentials: CredentialFunc(func() (string, error) {
+				return token.Load().(string), nil
+			}),
+		})
+
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Equal(t, "Bearer first", authorization.Load())
+
+		token.Store("second")
+		_, err = index.Namespace("ns").Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Equal(t, "Bearer second", authorization.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		authorization.Store("")
+		index := NewIndexWith(Options{
+			Url: server.URL,
+			Credentials: CredentialFunc(func() (string, error) {
+				return "", errors.New("vault is sealed")
+			}),
+		})
+
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.EqualError(t, err, "failed to get credentials: vault is sealed")
+		require.Equal(t, "", authorization.Load())
+	})
+
+	t.Run("static", func(t *testing.T) {
+		index := NewIndexWith(Options{
+			Url:         server.URL,
+			Token:       "ignored",
+			Credentials: StaticCredentials("static"),
+		})
+
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Equal(t, "Bearer static", authorization.Load())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_VECTOR_TOKEN", "env")
+		token, err := EnvCredentials("TEST_VECTOR_TOKEN").Token()
+		require.NoError(t, err)
+		require.Equal(t, "env", token)
+
+		t.Setenv("TEST_VECTOR_TOKEN", "")
+		_, err = EnvCredentials("TEST_VECTOR_TOKEN").Token()
+		require.Error(t, err)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		credentials := FileCredentials(path, 10*time.Millisecond)
+
+		_, err := credentials.Token()
+		require.Error(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+		token, err := credentials.Token()
+		require.NoError(t, err)
+		require.Equal(t, "first", token)
+
+		require.NoError(t, os.WriteFile(path, []byte("second-token\n"), 0o600))
+		time.Sleep(20 * time.Millisecond)
+		token, err = credentials.Token()
+		require.NoError(t, err)
+		require.Equal(t, "second-token", token)
+
+		// The last token is kept while the file cannot be read.
+		require.NoError(t, os.Remove(path))
+		time.Sleep(20 * time.Millisecond)
+		token, err = credentials.Token()
+		require.NoError(t, err)
+		require.Equal(t, "second-token", token)
+	})
+}
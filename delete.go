@@ -14,7 +14,9 @@ func (ix *Index) DeleteMany(ids []string) (count int, err error) {
 }
 
 func (ix *Index) deleteInternal(id string, ns string) (ok bool, err error) {
-	data, err := ix.sendBytes(buildPath(deletePath, ns), []byte(id))
+	defer ix.invalidateQueryCache(ns)
+
+	data, err := ix.sendRequest(newRequest(buildPath(deletePath, ns), []byte(id), id))
 	if err != nil {
 		return
 	}
@@ -29,6 +31,8 @@ func (ix *Index) deleteInternal(id string, ns string) (ok bool, err error) {
 }
 
 func (ix *Index) deleteManyInternal(ids []string, ns string) (count int, err error) {
+	defer ix.invalidateQueryCache(ns)
+
 	data, err := ix.sendJson(buildPath(deletePath, ns), ids)
 	if err != nil {
 		return
@@ -9,11 +9,28 @@ func (ix *Index) Fetch(f Fetch) (vectors []Vector, err error) {
 	return ix.fetchInternal(f, defaultNamespace)
 }
 
+// FetchIterator returns an iterator over the vectors in the default namespace with the ids
+// passed into f. The vectors are decoded as they are read from the response, which avoids
+// holding the whole response in memory for large fetches with IncludeVectors.
+// Vectors are returned in the order of the ids, and missing vectors are returned as
+// vectors with empty ids. The request is sent on the first call to Next.
+func (ix *Index) FetchIterator(f Fetch) *VectorIterator {
+	return ix.fetchIteratorInternal(f, defaultNamespace)
+}
+
+func (ix *Index) fetchIteratorInternal(f Fetch, ns string) *VectorIterator {
+	return &VectorIterator{
+		open: func() (*vectorStream, error) {
+			return ix.openVectorStream(buildPath(fetchPath, ns), f, false)
+		},
+	}
+}
+
 func (ix *Index) fetchInternal(f Fetch, ns string) (vectors []Vector, err error) {
 	data, err := ix.sendJson(buildPath(fetchPath, ns), f)
 	if err != nil {
 		return
 	}
-	vectors, err = parseResponse[[]Vector](data)
+	vectors, err = parseVectors(data)
 	return
 }
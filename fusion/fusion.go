@@ -0,0 +1,191 @@
+// Package fusion implements client-side fusion of scored result lists.
+//
+// Upstash Vector applies the fusion algorithms only between the dense
+// and sparse components of a single hybrid index. This package applies
+// the same algorithms to arbitrary result lists, such as the results of
+// separate dense and sparse indexes, results from different namespaces,
+// or results coming from an external keyword search engine.
+package fusion
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/upstash/vector-go"
+)
+
+// DefaultK is the constant used in reciprocal rank fusion
+// when no other value is provided. It is the same
+// constant used by Upstash Vector.
+const DefaultK = 60
+
+// Input is a list of scores to be fused with the other inputs.
+type Input struct {
+	// Scores to fuse. They don't need to be sorted,
+	// as they are sorted in descending order of score
+	// before ranks or distributions are calculated.
+	// If the same id is present more than once, only
+	// the one with the highest score is used.
+	Scores []vector.VectorScore
+
+	// Weight of the input, which the fused scores coming
+	// from this input are multiplied with.
+	// If not provided, defaults to 1.
+	Weight float32
+}
+
+// Fuse fuses the inputs with the given algorithm and returns the fused
+// scores in descending order of score.
+// If the algorithm is not provided, defaults to RRF.
+func Fuse(algorithm vector.FusionAlgorithm, inputs ...Input) ([]vector.VectorScore, error) {
+	switch algorithm {
+	case "", vector.FusionAlgorithmRRF:
+		return RRF(DefaultK, inputs...), nil
+	case vector.FusionAlgorithmDBSF:
+		return DBSF(inputs...), nil
+	default:
+		return nil, fmt.Errorf("unknown fusion algorithm: %s", algorithm)
+	}
+}
+
+// RRF fuses the inputs with the reciprocal rank fusion.
+//
+// Each sorted score of an input is mapped to 1 / (rank + k), where rank
+// is the 1-based order of the score in the input, and multiplied by the
+// weight of the input. If k is not positive, DefaultK is used.
+//
+// Then, the mapped scores of the same vector from different inputs are
+// added, and the result is returned in descending order of the fused score.
+func RRF(k int, inputs ...Input) []vector.VectorScore {
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	f := newFuser()
+	for _, input := range inputs {
+		scores := sortedUnique(input.Scores)
+		weight := weightOf(input)
+		for i, score := range scores {
+			f.add(score, weight/float32(i+1+k))
+		}
+	}
+	return f.result()
+}
+
+// DBSF fuses the inputs with the distribution based score fusion.
+//
+// Each score of an input is normalized as
+// (s - (mean - 3 * stddev)) / ((mean + 3 * stddev) - (mean - 3 * stddev))
+// where s is the score, and mean and stddev are calculated over the scores
+// of the input, and multiplied by the weight of the input. When all the
+// scores of an input are the same, they are all normalized to 0.5.
+//
+// Then, the normalized scores of the same vector from different inputs are
+// added, and the result is returned in descending order of the fused score.
+func DBSF(inputs ...Input) []vector.VectorScore {
+	f := newFuser()
+	for _, input := range inputs {
+		scores := sortedUnique(input.Scores)
+		if len(scores) == 0 {
+			continue
+		}
+
+		var sum float64
+		for _, score := range scores {
+			sum += float64(score.Score)
+		}
+		mean := sum / float64(len(scores))
+
+		var variance float64
+		for _, score := range scores {
+			d := float64(score.Score) - mean
+			variance += d * d
+		}
+		stddev := math.Sqrt(variance / float64(len(scores)))
+
+		lo := mean - 3*stddev
+		hi := mean + 3*stddev
+		weight := float64(weightOf(input))
+		for _, score := range scores {
+			normalized := 0.5
+			if hi > lo {
+				normalized = (float64(score.Score) - lo) / (hi - lo)
+			}
+			f.add(score, float32(normalized*weight))
+		}
+	}
+	return f.result()
+}
+
+func weightOf(input Input) float32 {
+	if input.Weight == 0 {
+		return 1
+	}
+	return input.Weight
+}
+
+// sortedUnique returns a copy of the scores sorted in descending order,
+// keeping only the highest score for each id.
+func sortedUnique(scores []vector.VectorScore) []vector.VectorScore {
+	sorted := make([]vector.VectorScore, len(scores))
+	copy(sorted, scores)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	seen := make(map[string]struct{}, len(sorted))
+	unique := sorted[:0]
+	for _, score := range sorted {
+		if _, ok := seen[score.Id]; ok {
+			continue
+		}
+		seen[score.Id] = struct{}{}
+		unique = append(unique, score)
+	}
+	return unique
+}
+
+type fuser struct {
+	scores []vector.VectorScore
+	ids    map[string]int
+}
+
+func newFuser() *fuser {
+	return &fuser{ids: map[string]int{}}
+}
+
+// add adds the fused score of the vector. The first occurrence of
+// a vector determines its values, and missing values are filled
+// from the later occurrences.
+func (f *fuser) add(score vector.VectorScore, fused float32) {
+	i, ok := f.ids[score.Id]
+	if !ok {
+		score.Score = fused
+		f.ids[score.Id] = len(f.scores)
+		f.scores = append(f.scores, score)
+		return
+	}
+
+	existing := &f.scores[i]
+	existing.Score += fused
+	if existing.Vector == nil {
+		existing.Vector = score.Vector
+	}
+	if existing.SparseVector == nil {
+		existing.SparseVector = score.SparseVector
+	}
+	if existing.Metadata == nil {
+		existing.Metadata = score.Metadata
+	}
+	if existing.Data == "" {
+		existing.Data = score.Data
+	}
+}
+
+func (f *fuser) result() []vector.VectorScore {
+	sort.SliceStable(f.scores, func(i, j int) bool {
+		return f.scores[i].Score > f.scores[j].Score
+	})
+	return f.scores
+}
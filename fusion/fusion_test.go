@@ -0,0 +1,127 @@
+package fusion
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go"
+)
+
+func ids(scores []vector.VectorScore) []string {
+	result := make([]string, len(scores))
+	for i, score := range scores {
+		result[i] = score.Id
+	}
+	return result
+}
+
+func TestRRF(t *testing.T) {
+	dense := []vector.VectorScore{
+		{Id: "a", Score: 0.9},
+		{Id: "b", Score: 0.8},
+		{Id: "c", Score: 0.7},
+	}
+	sparse := []vector.VectorScore{
+		{Id: "c", Score: 12},
+		{Id: "d", Score: 10, Data: "d data"},
+	}
+
+	t.Run("scores", func(t *testing.T) {
+		scores := RRF(0, Input{Scores: dense}, Input{Scores: sparse})
+		require.Equal(t, []string{"c", "a", "b", "d"}, ids(scores))
+		require.InDelta(t, 1.0/63+1.0/61, scores[0].Score, 1e-6)
+		require.InDelta(t, 1.0/61, scores[1].Score, 1e-6)
+		require.InDelta(t, 1.0/62, scores[2].Score, 1e-6)
+		require.InDelta(t, 1.0/62, scores[3].Score, 1e-6)
+		require.Equal(t, "d data", scores[3].Data)
+	})
+
+	t.Run("custom k", func(t *testing.T) {
+		scores := RRF(1, Input{Scores: dense})
+		require.InDelta(t, 0.5, scores[0].Score, 1e-6)
+	})
+
+	t.Run("unsorted input with duplicates", func(t *testing.T) {
+		scores := RRF(0, Input{Scores: []vector.VectorScore{
+			{Id: "a", Score: 0.1},
+			{Id: "b", Score: 0.5},
+			{Id: "a", Score: 0.9},
+		}})
+		require.Equal(t, []string{"a", "b"}, ids(scores))
+		require.InDelta(t, 1.0/61, scores[0].Score, 1e-6)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		scores := RRF(0, Input{Scores: dense, Weight: 0.1}, Input{Scores: sparse, Weight: 2})
+		require.Equal(t, []string{"c", "d", "a", "b"}, ids(scores))
+		require.InDelta(t, 0.1/63+2.0/61, scores[0].Score, 1e-6)
+	})
+
+	t.Run("does not modify input", func(t *testing.T) {
+		input := []vector.VectorScore{{Id: "x", Score: 0.1}, {Id: "y", Score: 0.2}}
+		RRF(0, Input{Scores: input})
+		require.Equal(t, "x", input[0].Id)
+		require.Equal(t, float32(0.1), input[0].Score)
+	})
+}
+
+func TestDBSF(t *testing.T) {
+	dense := []vector.VectorScore{
+		{Id: "a", Score: 0.9},
+		{Id: "b", Score: 0.8},
+		{Id: "c", Score: 0.7},
+	}
+	sparse := []vector.VectorScore{
+		{Id: "c", Score: 12},
+		{Id: "d", Score: 10},
+	}
+
+	normalize := func(s float64, values ...float64) float64 {
+		var mean float64
+		for _, v := range values {
+			mean += v
+		}
+		mean /= float64(len(values))
+		var variance float64
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+		stddev := math.Sqrt(variance / float64(len(values)))
+		return (s - (mean - 3*stddev)) / (6 * stddev)
+	}
+
+	t.Run("scores", func(t *testing.T) {
+		scores := DBSF(Input{Scores: dense}, Input{Scores: sparse})
+		require.Equal(t, []string{"c", "a", "b", "d"}, ids(scores))
+		require.InDelta(t, normalize(0.7, 0.9, 0.8, 0.7)+normalize(12, 12, 10), scores[0].Score, 1e-5)
+		require.InDelta(t, normalize(0.9, 0.9, 0.8, 0.7), scores[1].Score, 1e-5)
+		require.InDelta(t, normalize(0.8, 0.9, 0.8, 0.7), scores[2].Score, 1e-5)
+		require.InDelta(t, normalize(10, 12, 10), scores[3].Score, 1e-5)
+	})
+
+	t.Run("same scores", func(t *testing.T) {
+		scores := DBSF(Input{Scores: []vector.VectorScore{{Id: "a", Score: 1}}})
+		require.Equal(t, float32(0.5), scores[0].Score)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		scores := DBSF(Input{Scores: dense, Weight: 3})
+		require.InDelta(t, 3*normalize(0.9, 0.9, 0.8, 0.7), scores[0].Score, 1e-5)
+	})
+}
+
+func TestFuse(t *testing.T) {
+	input := Input{Scores: []vector.VectorScore{{Id: "a", Score: 1}}}
+
+	scores, err := Fuse("", input)
+	require.NoError(t, err)
+	require.Equal(t, RRF(DefaultK, input), scores)
+
+	scores, err = Fuse(vector.FusionAlgorithmDBSF, input)
+	require.NoError(t, err)
+	require.Equal(t, DBSF(input), scores)
+
+	_, err = Fuse("unknown", input)
+	require.Error(t, err)
+}
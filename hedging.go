@@ -0,0 +1,231 @@
+package vector
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHedgingPercentile   = 0.95
+	defaultHedgingInitialDelay = 100 * time.Millisecond
+	hedgingLatencySamples      = 256
+	hedgingMinSamples          = 20
+)
+
+// hedgedOperations are the idempotent read operations that can be hedged.
+var hedgedOperations = map[string]bool{
+	"query":      true,
+	"query-data": true,
+	"fetch":      true,
+	"range":      true,
+	"info":       true,
+}
+
+// HedgingOptions configures the hedging of the idempotent reads, which are
+// the queries, fetches, ranges and info requests.
+//
+// When the response of a hedged request is not received within the given
+// percentile of the recent latencies of its operation, a second request is
+// sent. The latencies are measured from when the first request is sent, and
+// the canceled first requests count as taking at least until they are
+// canceled. The response that is received first is used, and the other
+// request is canceled. The hedged requests are reported to the instrumentation with
+// their attempt numbers.
+type HedgingOptions struct {
+	// Percentile of the recent latencies of an operation, between 0 and 1,
+	// after which the second request is sent.
+	// If not provided, defaults to 0.95.
+	Percentile float64
+
+	// Delay after which the second request is sent, until enough
+	// latencies of the operation are observed.
+	// If not provided, defaults to 100 milliseconds.
+	InitialDelay time.Duration
+
+	// Minimum delay after which the second request is sent.
+	// If not provided, the delay is not limited from below.
+	MinDelay time.Duration
+
+	// Maximum delay after which the second request is sent. The requests
+	// are not hedged if the delay would exceed the timeout of the HTTP client.
+	// If not provided, the delay is not limited from above.
+	MaxDelay time.Duration
+}
+
+type hedger struct {
+	options HedgingOptions
+	timeout time.Duration
+
+	mu        sync.Mutex
+	latencies map[string]*latencies
+}
+
+func newHedger(options HedgingOptions, timeout time.Duration) *hedger {
+	if options.Percentile <= 0 || options.Percentile > 1 {
+		options.Percentile = defaultHedgingPercentile
+	}
+	if options.InitialDelay <= 0 {
+		options.InitialDelay = defaultHedgingInitialDelay
+	}
+	return &hedger{
+		options:   options,
+		timeout:   timeout,
+		latencies: map[string]*latencies{},
+	}
+}
+
+// latencies is a ring buffer of the recent latencies of an operation.
+type latencies struct {
+	samples []time.Duration
+	next    int
+}
+
+// delay returns the delay after which the second request of the operation
+// is sent, and reports whether the request should be hedged.
+func (h *hedger) delay(operation string) (delay time.Duration, ok bool) {
+	h.mu.Lock()
+	l := h.latencies[operation]
+	if l == nil || len(l.samples) < hedgingMinSamples {
+		delay = h.options.InitialDelay
+	} else {
+		sorted := slices.Clone(l.samples)
+		slices.Sort(sorted)
+		delay = sorted[min(len(sorted)-1, int(h.options.Percentile*float64(len(sorted))))]
+	}
+	h.mu.Unlock()
+
+	delay = max(delay, h.options.MinDelay)
+	if h.options.MaxDelay > 0 {
+		delay = min(delay, h.options.MaxDelay)
+	}
+	return delay, h.timeout <= 0 || delay < h.timeout
+}
+
+// observe records the latency of a request of the operation.
+func (h *hedger) observe(operation string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	l := h.latencies[operation]
+	if l == nil {
+		l = &latencies{samples: make([]time.Duration, 0, hedgingLatencySamples)}
+		h.latencies[operation] = l
+	}
+	if len(l.samples) < hedgingLatencySamples {
+		l.samples = append(l.samples, latency)
+		return
+	}
+	l.samples[l.next] = latency
+	l.next = (l.next + 1) % hedgingLatencySamples
+}
+
+type hedgedResult struct {
+	attempt    int
+	body       io.ReadCloser
+	statusCode int
+	err        error
+}
+
+// openHedged sends the request, and sends a second one if the response of
+// the first one is not received within the hedging delay of the operation.
+func (ix *Index) openHedged(r *request) (body io.ReadCloser, statusCode int, err error) {
+	delay, ok := ix.hedger.delay(r.operation)
+	start := time.Now()
+	if !ok {
+		body, statusCode, err = ix.openAttempt(r, 1, nil)
+		if err == nil {
+			ix.hedger.observe(r.operation, time.Since(start))
+		}
+		return
+	}
+
+	results := make(chan hedgedResult, 2)
+	var cancels []context.CancelFunc
+	send := func(attempt int) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels = append(cancels, cancel)
+		go func() {
+			body, statusCode, err := ix.openAttempt(r, attempt, ctx)
+			results <- hedgedResult{attempt: attempt, body: body, statusCode: statusCode, err: err}
+		}()
+	}
+
+	send(1)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending := 1
+	firstFailed := false
+	for {
+		select {
+		case <-timer.C:
+			send(2)
+			pending++
+		case res := <-results:
+			pending--
+			if res.err != nil {
+				firstFailed = firstFailed || res.attempt == 1
+				// The hedged requests are not retries, so the second request
+				// is not sent if the first one fails before the delay.
+				if pending > 0 {
+					err = res.err
+					continue
+				}
+				for _, cancel := range cancels {
+					cancel()
+				}
+				if err == nil {
+					err = res.err
+				}
+				return nil, 0, err
+			}
+
+			// The latencies are those of the first attempts, timed from when
+			// the request is sent. When the first attempt is canceled, its
+			// latency is at least the time until then, so that the delays do
+			// not keep decreasing with the latencies of the second attempts.
+			if !firstFailed {
+				ix.hedger.observe(r.operation, time.Since(start))
+			}
+
+			for i, cancel := range cancels {
+				if i != res.attempt-1 {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go func() {
+					for ; pending > 0; pending-- {
+						if loser := <-results; loser.body != nil {
+							loser.body.Close()
+						}
+					}
+				}()
+			}
+			return &cancelingBody{ReadCloser: res.body, cancel: cancels[res.attempt-1]}, res.statusCode, nil
+		}
+	}
+}
+
+// openAttempt sends an attempt of the request.
+func (ix *Index) openAttempt(r *request, attempt int, ctx context.Context) (body io.ReadCloser, statusCode int, err error) {
+	ar := *r
+	ar.attempt, ar.ctx = attempt, ctx
+	return ix.openHTTP(&ar)
+}
+
+// cancelingBody is a response body that cancels
+// the context of its request when it is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,168 @@
+package vector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type attemptRecorder struct {
+	mu       sync.Mutex
+	attempts []int
+	errs     []error
+}
+
+func (a *attemptRecorder) StartRequest(info RequestInfo) func(RequestResult) {
+	return func(result RequestResult) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.attempts = append(a.attempts, info.Attempt)
+		a.errs = append(a.errs, result.Err)
+	}
+}
+
+func TestHedging(t *testing.T) {
+	var requests atomic.Int32
+	canceled := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the canceled requests only after their bodies are read.
+		_, _ = io.ReadAll(r.Body)
+		if requests.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				canceled <- struct{}{}
+				return
+			case <-time.After(time.Second):
+			}
+		}
+		if r.URL.Path == upsertPath {
+			_, _ = w.Write([]byte(`{"result":"Success"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[{"id":"a","score":1}]}`))
+	}))
+	defer server.Close()
+
+	recorder := &attemptRecorder{}
+	index := NewIndexWith(Options{
+		Url:             server.URL,
+		Token:           "token",
+		Instrumentation: recorder,
+		Hedging: &HedgingOptions{
+			InitialDelay: 20 * time.Millisecond,
+		},
+	})
+
+	t.Run("slow request", func(t *testing.T) {
+		start := time.Now()
+		scores, err := index.Query(Query{TopK: 1})
+		require.NoError(t, err)
+		require.Equal(t, []VectorScore{{Id: "a", Score: 1}}, scores)
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.Equal(t, int32(2), requests.Load())
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("slow request is not canceled")
+		}
+
+		require.Eventually(t, func() bool {
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			return len(recorder.attempts) == 2
+		}, time.Second, 10*time.Millisecond)
+		require.ElementsMatch(t, []int{1, 2}, recorder.attempts)
+	})
+
+	t.Run("fast request", func(t *testing.T) {
+		requests.Store(1)
+		_, err := index.Query(Query{TopK: 1})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("write", func(t *testing.T) {
+		requests.Store(0)
+		start := time.Now()
+		err := index.Upsert(Upsert{Id: "a"})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), time.Second)
+		require.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func TestHedgingSustained(t *testing.T) {
+	// The first attempts are slow, and the second ones are fast.
+	var inFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		if inFlight.Add(1) == 1 {
+			defer inFlight.Add(-1)
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Second):
+			}
+		} else {
+			inFlight.Add(-1)
+		}
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	index := NewIndexWith(Options{
+		Url:   server.URL,
+		Token: "token",
+		Hedging: &HedgingOptions{
+			InitialDelay: 20 * time.Millisecond,
+		},
+	})
+
+	for i := 0; i < 2*hedgingMinSamples; i++ {
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return inFlight.Load() == 0
+		}, time.Second, time.Millisecond)
+	}
+
+	// The latencies of the canceled first attempts are at least the delays
+	// after which they are hedged, so the delay does not decrease.
+	delay, ok := index.hedger.delay("fetch")
+	require.True(t, ok)
+	require.GreaterOrEqual(t, delay, 20*time.Millisecond)
+}
+
+func TestHedgingDelay(t *testing.T) {
+	h := newHedger(HedgingOptions{Percentile: 0.9, MinDelay: 5 * time.Millisecond}, time.Second)
+
+	delay, ok := h.delay("query")
+	require.True(t, ok)
+	require.Equal(t, defaultHedgingInitialDelay, delay)
+
+	for i := 1; i <= 100; i++ {
+		h.observe("query", time.Duration(i)*time.Millisecond)
+	}
+	delay, ok = h.delay("query")
+	require.True(t, ok)
+	require.Equal(t, 91*time.Millisecond, delay)
+
+	for i := 0; i < hedgingLatencySamples; i++ {
+		h.observe("fetch", time.Millisecond)
+	}
+	delay, _ = h.delay("fetch")
+	require.Equal(t, 5*time.Millisecond, delay)
+
+	for i := 0; i < hedgingLatencySamples; i++ {
+		h.observe("fetch", 2*time.Second)
+	}
+	_, ok = h.delay("fetch")
+	require.False(t, ok)
+}
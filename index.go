@@ -2,13 +2,17 @@ package vector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"runtime"
+	"strings"
+	"sync"
 )
 
 const (
@@ -22,10 +26,66 @@ type Options struct {
 	Url string
 
 	// Token of the Upstash Vector index.
+	// It is not required if Credentials is provided.
 	Token string
 
+	// Optional provider of the token of the index, which is consulted
+	// for each request, so that the token can be rotated.
+	// If provided, Token is ignored.
+	Credentials CredentialProvider
+
 	// The HTTP client to use for requests.
 	Client *http.Client
+
+	// Optional configuration of the query result cache.
+	// If not provided, query results are not cached.
+	QueryCache *QueryCacheOptions
+
+	// Whether to validate the vectors of upserts, updates and queries
+	// on the client before sending them. The dimension and the similarity
+	// function of the index are fetched and cached on the first use.
+	// Invalid vectors are reported with a *ValidationError.
+	Validate bool
+
+	// Optional configuration of the request body compression.
+	// If not provided, request bodies are not compressed.
+	Compression *CompressionOptions
+
+	// Optional instrumentation that is notified of the requests,
+	// to create spans and record metrics for them.
+	Instrumentation Instrumentation
+
+	// Optional logger for the requests and responses.
+	// If not provided, nothing is logged.
+	Logger *slog.Logger
+
+	// Verbosity of the logs.
+	// If not provided, defaults to LogErrors.
+	LogVerbosity LogVerbosity
+
+	// Whether to log the values of the dense and sparse vectors in the
+	// request and response bodies. If not set, they are redacted.
+	LogVectors bool
+
+	// Middlewares the requests pass through, in order. The first
+	// middleware sees the requests first and the responses last.
+	Middlewares []Middleware
+
+	// Optional configuration of the client side rate limits of the requests.
+	// If not provided, the requests are not limited.
+	RateLimit *RateLimitOptions
+
+	// Optional configuration of the circuit breaker of the requests.
+	// If not provided, the requests are always sent.
+	CircuitBreaker *CircuitBreakerOptions
+
+	// Optional configuration of the hedging of the idempotent reads.
+	// If not provided, the requests are not hedged.
+	Hedging *HedgingOptions
+
+	// Whether the index client is read-only. If set, the operations that
+	// modify the index fail with a *ReadOnlyError without being sent.
+	ReadOnly bool
 }
 
 func (o *Options) init() {
@@ -35,7 +95,7 @@ func (o *Options) init() {
 	if o.Url == "" {
 		panic("Missing Upstash Vector URL")
 	}
-	if o.Token == "" {
+	if o.Token == "" && o.Credentials == nil {
 		panic("Missing Upstash Vector Token")
 	}
 }
@@ -63,9 +123,32 @@ func NewIndexFromEnv() *Index {
 func NewIndexWith(options Options) *Index {
 	options.init()
 	index := &Index{
-		url:    options.Url,
-		token:  options.Token,
-		client: options.Client,
+		url:             options.Url,
+		token:           options.Token,
+		credentials:     options.Credentials,
+		client:          options.Client,
+		validate:        options.Validate,
+		instrumentation: options.Instrumentation,
+		logger:          options.Logger,
+		logVerbosity:    options.LogVerbosity,
+		logVectors:      options.LogVectors,
+		middlewares:     options.Middlewares,
+		readOnly:        options.ReadOnly,
+	}
+	if options.QueryCache != nil {
+		index.queryCache = newQueryCache(*options.QueryCache)
+	}
+	if options.Compression != nil {
+		index.compressor = newCompressor(*options.Compression)
+	}
+	if options.RateLimit != nil {
+		index.rateLimiter = newRateLimiter(*options.RateLimit)
+	}
+	if options.CircuitBreaker != nil {
+		index.circuitBreaker = newCircuitBreaker(*options.CircuitBreaker)
+	}
+	if options.Hedging != nil {
+		index.hedger = newHedger(*options.Hedging, options.Client.Timeout)
 	}
 	index.generateHeaders()
 	return index
@@ -73,36 +156,268 @@ func NewIndexWith(options Options) *Index {
 
 // Index is a client for Upstash Vector index.
 type Index struct {
-	url     string
-	token   string
-	client  *http.Client
-	headers http.Header
+	url             string
+	token           string
+	credentials     CredentialProvider
+	client          *http.Client
+	headers         http.Header
+	queryCache      *queryCache
+	validate        bool
+	compressor      *compressor
+	instrumentation Instrumentation
+	logger          *slog.Logger
+	logVerbosity    LogVerbosity
+	logVectors      bool
+	middlewares     []Middleware
+	rateLimiter     *rateLimiter
+	circuitBreaker  *circuitBreaker
+	hedger          *hedger
+	readOnly        bool
+
+	infoMu sync.Mutex
+	info   *IndexInfo
+}
+
+// request is a request to be sent to the index.
+type request struct {
+	// Path of the request, including the namespace.
+	path string
+
+	// Name of the operation, which is the first segment of the path.
+	operation string
+
+	// Namespace of the request, if any.
+	namespace string
+
+	// Request body.
+	body []byte
+
+	// The object the body is encoded from, if any.
+	payload any
+
+	// When not nil, release is called once the body is no longer used,
+	// so that the buffer of the body can be reused.
+	release func()
+
+	// Additional headers of the request.
+	header http.Header
+
+	// Number of the attempt for the request, starting from 1.
+	// It is 0 for the requests that are not hedged.
+	attempt int
+
+	// When not nil, the context the request is sent with.
+	ctx context.Context
+}
+
+func newRequest(path string, body []byte, payload any) *request {
+	operation, namespace, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return &request{
+		path:      path,
+		operation: operation,
+		namespace: namespace,
+		body:      body,
+		payload:   payload,
+	}
 }
 
 func (ix *Index) sendJson(path string, obj any) (data []byte, err error) {
-	if data, err = json.Marshal(obj); err != nil {
+	body, err := json.Marshal(obj)
+	if err != nil {
 		return
 	}
-	return ix.sendBytes(path, data)
+	return ix.sendRequest(newRequest(path, body, obj))
 }
 
 func (ix *Index) sendBytes(path string, obj []byte) (data []byte, err error) {
-	return ix.send(path, bytes.NewReader(obj))
+	return ix.sendRequest(newRequest(path, obj, nil))
+}
+
+// sendRequest sends the request and reads the response body.
+func (ix *Index) sendRequest(r *request) (data []byte, err error) {
+	body, err := ix.open(r)
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	data, err = io.ReadAll(body)
+	return
 }
 
-func (ix *Index) send(path string, r io.Reader) (data []byte, err error) {
-	request, err := http.NewRequest(http.MethodPost, ix.url+path, r)
+// openJson sends the object as the request body and returns the
+// response body, which should be closed after it is read.
+func (ix *Index) openJson(path string, obj any) (body io.ReadCloser, err error) {
+	data, err := json.Marshal(obj)
 	if err != nil {
 		return
 	}
+	return ix.open(newRequest(path, data, obj))
+}
+
+// open sends the request through the middlewares, if any, and returns
+// the response body, which should be closed after it is read.
+func (ix *Index) open(r *request) (body io.ReadCloser, err error) {
+	if err = ix.checkWritable(r.operation); err != nil {
+		if r.release != nil {
+			r.release()
+		}
+		return
+	}
+	if len(ix.middlewares) > 0 {
+		return ix.openWithMiddlewares(r)
+	}
+	body, _, err = ix.roundTrip(r)
+	return
+}
+
+// roundTrip sends the request, hedging it if it is enabled for the operation.
+func (ix *Index) roundTrip(r *request) (body io.ReadCloser, statusCode int, err error) {
+	if ix.hedger != nil && hedgedOperations[r.operation] {
+		return ix.openHedged(r)
+	}
+	return ix.openHTTP(r)
+}
+
+// openHTTP sends the request, compressing its body if needed, and returns
+// the decompressed response body, which should be closed after it is read,
+// along with the status code of the response.
+func (ix *Index) openHTTP(r *request) (body io.ReadCloser, statusCode int, err error) {
+	obj, release := r.body, r.release
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var authorization string
+	if ix.credentials != nil {
+		if authorization, err = ix.authorization(); err != nil {
+			if release != nil {
+				release()
+			}
+			return
+		}
+	}
+
+	sent := false
+	if ix.circuitBreaker != nil {
+		var done func(error, int)
+		if done, err = ix.circuitBreaker.allow(); err != nil {
+			if release != nil {
+				release()
+			}
+			return
+		}
+		defer func() {
+			switch {
+			case !sent:
+				done(errRequestNotSent, 0)
+			case err != nil:
+				done(err, statusCode)
+			default:
+				body = &breakerBody{ReadCloser: body, statusCode: statusCode, done: done}
+			}
+		}()
+	}
+
+	if ix.rateLimiter != nil {
+		var done func()
+		if done, err = ix.rateLimiter.acquire(ctx, r.operation); err != nil {
+			if release != nil {
+				release()
+			}
+			return
+		}
+		defer func() {
+			if err != nil {
+				done()
+			} else {
+				body = &limitedBody{ReadCloser: body, done: done}
+			}
+		}()
+	}
+
+	// The uncompressed body is released only once the request is started,
+	// since it is still read to log the request.
+	var contentEncoding string
+	var releaseUncompressed func()
+	if ix.compressor != nil && len(obj) >= ix.compressor.threshold {
+		compressed, err := ix.compressor.codec.Compress(nil, obj)
+		if err != nil {
+			if release != nil {
+				release()
+			}
+			return nil, 0, err
+		}
+		obj, contentEncoding = compressed, ix.compressor.codec.ContentEncoding()
+		releaseUncompressed, release = release, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, ix.url+r.path, bytes.NewReader(obj))
+	if err != nil {
+		if release != nil {
+			release()
+		}
+		if releaseUncompressed != nil {
+			releaseUncompressed()
+		}
+		return
+	}
 	request.Header = ix.headers
+	if authorization != "" || contentEncoding != "" || len(r.header) > 0 {
+		request.Header = ix.headers.Clone()
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		for key, values := range r.header {
+			request.Header[key] = values
+		}
+		if contentEncoding != "" {
+			request.Header.Set("Content-Encoding", contentEncoding)
+		}
+	}
+	if release != nil {
+		// The transport closes the request body once it is done with it,
+		// which might be after the response is returned.
+		request.Body = &releasingBody{Reader: bytes.NewReader(obj), release: release}
+		request.GetBody = nil
+	}
+
+	trace := ix.startRequest(r, request.Header)
+	if releaseUncompressed != nil {
+		releaseUncompressed()
+	}
+	sent = true
 	response, err := ix.client.Do(request)
 	if err != nil {
+		if trace != nil {
+			trace.finish(0, 0, nil, err)
+		}
 		return
 	}
-	defer response.Body.Close()
-	data, err = io.ReadAll(response.Body)
-	return
+	if body, err = ix.compressor.decompressBody(response.Header.Get("Content-Encoding"), response.Body); err != nil {
+		response.Body.Close()
+		if trace != nil {
+			trace.finish(response.StatusCode, 0, nil, err)
+		}
+		return
+	}
+	if trace != nil {
+		body = newTracedBody(body, trace, response.StatusCode)
+	}
+	return body, response.StatusCode, nil
+}
+
+// releasingBody is a request body that calls release when it is closed.
+type releasingBody struct {
+	*bytes.Reader
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	b.once.Do(b.release)
+	return nil
 }
 
 func parseResponse[T any](data []byte) (t T, err error) {
@@ -119,7 +434,10 @@ func parseResponse[T any](data []byte) (t T, err error) {
 
 func (ix *Index) generateHeaders() {
 	headers := http.Header{}
-	headers.Add("Authorization", "Bearer "+ix.token)
+	// With a credential provider, the token is set for each request.
+	if ix.credentials == nil {
+		headers.Add("Authorization", "Bearer "+ix.token)
+	}
 	headers.Add("Upstash-Telemetry-Runtime", fmt.Sprintf("vector-go@%s", runtime.Version()))
 	var platform string
 	if os.Getenv("VERCEL") != "" {
@@ -130,6 +448,9 @@ func (ix *Index) generateHeaders() {
 		platform = "unknown"
 	}
 	headers.Add("Upstash-Telemetry-Platform", platform)
+	if ix.compressor != nil {
+		headers.Add("Accept-Encoding", ix.compressor.acceptEncoding())
+	}
 	ix.headers = headers
 }
 
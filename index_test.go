@@ -150,6 +150,46 @@ func (tc *testClient) ResumableQueryData(q ResumableQueryData) (scores []VectorS
 	}
 }
 
+func (tc *testClient) QueryMMR(q QueryMMR) (scores []VectorScore, err error) {
+	if tc.namespaceName == defaultNamespace {
+		return tc.index.QueryMMR(q)
+	} else {
+		return tc.namespace.QueryMMR(q)
+	}
+}
+
+func (tc *testClient) QueryById(q QueryById) (scores []VectorScore, err error) {
+	if tc.namespaceName == defaultNamespace {
+		return tc.index.QueryById(q)
+	} else {
+		return tc.namespace.QueryById(q)
+	}
+}
+
+func (tc *testClient) Recommend(r Recommend) (scores []VectorScore, err error) {
+	if tc.namespaceName == defaultNamespace {
+		return tc.index.Recommend(r)
+	} else {
+		return tc.namespace.Recommend(r)
+	}
+}
+
+func (tc *testClient) QueryGroups(q QueryGroups) (groups []ScoreGroup, err error) {
+	if tc.namespaceName == defaultNamespace {
+		return tc.index.QueryGroups(q)
+	} else {
+		return tc.namespace.QueryGroups(q)
+	}
+}
+
+func (tc *testClient) RangeIterator(r Range) *RangeIterator {
+	if tc.namespaceName == defaultNamespace {
+		return tc.index.RangeIterator(r)
+	} else {
+		return tc.namespace.RangeIterator(r)
+	}
+}
+
 func (tc *testClient) Info() (info IndexInfo, err error) {
 	return tc.index.Info()
 }
@@ -17,3 +17,21 @@ func (ix *Index) Info() (info IndexInfo, err error) {
 	info, err = parseResponse[IndexInfo](data)
 	return
 }
+
+// cachedInfo returns the information about the index fetched on the first use.
+// Only the fields that do not change over the lifetime of the index, such as
+// the dimension and the similarity function, should be used from it.
+func (ix *Index) cachedInfo() (info IndexInfo, err error) {
+	ix.infoMu.Lock()
+	defer ix.infoMu.Unlock()
+
+	if ix.info != nil {
+		return *ix.info, nil
+	}
+
+	if info, err = ix.Info(); err != nil {
+		return
+	}
+	ix.info = &info
+	return
+}
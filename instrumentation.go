@@ -0,0 +1,220 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Instrumentation is notified of the requests sent to the index, so that
+// spans can be created and metrics can be recorded for them.
+//
+// All the requests sent by Index, Namespace and ResumableQueryHandle are
+// reported, including the ones sent for the higher level operations such as
+// QueryNamespaces or QueryMMR. The otelvector module implements it with
+// OpenTelemetry, without adding an OpenTelemetry dependency to this package.
+type Instrumentation interface {
+	// StartRequest is called before a request is sent. The returned
+	// function, if not nil, is called once with the result of the request,
+	// after the response is read or the request fails.
+	StartRequest(info RequestInfo) func(result RequestResult)
+}
+
+// RequestInfo describes a request sent to the index.
+type RequestInfo struct {
+	// Name of the operation, such as "query", "upsert" or "resumable-query-next".
+	Operation string
+
+	// Namespace of the request. It is empty for the default namespace,
+	// and for the operations that are not scoped to a namespace.
+	Namespace string
+
+	// Path of the request, relative to the URL of the index.
+	Path string
+
+	// TopK of the queries, the additional K of the resumable query pages,
+	// or the limit of the ranges. It is zero for the other operations.
+	TopK int
+
+	// Number of the items in the request, such as the number of the
+	// upserted vectors, fetched or deleted ids, or queries.
+	Items int
+
+	// Size of the request body in bytes, before compression.
+	RequestSize int
+
+	// Number of the attempt for the request, starting from 1.
+	Attempt int
+}
+
+// RequestResult describes the result of a request sent to the index.
+type RequestResult struct {
+	// HTTP status code of the response, or 0 if no response is received.
+	StatusCode int
+
+	// Size of the response body in bytes, after decompression.
+	ResponseSize int
+
+	// Time passed from sending the request until the response body is read.
+	Duration time.Duration
+
+	// Error that occurred while sending the request or reading the
+	// response, or the error returned from the server.
+	Err error
+}
+
+// maxErrorBodySize is the size of the response body that is kept for
+// reporting the error message of the failed requests.
+const maxErrorBodySize = 4 * 1024
+
+// requestInfo returns the information of the request reported to the instrumentation.
+func requestInfo(r *request) RequestInfo {
+	info := RequestInfo{
+		Operation:   r.operation,
+		Namespace:   r.namespace,
+		Path:        r.path,
+		RequestSize: len(r.body),
+		Attempt:     max(r.attempt, 1),
+	}
+
+	switch p := r.payload.(type) {
+	case Query:
+		info.TopK, info.Items = p.TopK, 1
+	case QueryData:
+		info.TopK, info.Items = p.TopK, 1
+	case ResumableQuery:
+		info.TopK, info.Items = p.TopK, 1
+	case ResumableQueryData:
+		info.TopK, info.Items = p.TopK, 1
+	case resumableQueryNext:
+		info.TopK = p.AdditionalK
+	case Range:
+		info.TopK = p.Limit
+	case Fetch:
+		info.Items = len(p.Ids)
+	case []Upsert:
+		info.Items = len(p)
+	case UpsertData:
+		info.Items = 1
+	case []UpsertData:
+		info.Items = len(p)
+	case Update:
+		info.Items = 1
+	case string:
+		info.Items = 1
+	case []string:
+		info.Items = len(p)
+	}
+	return info
+}
+
+// requestTrace reports the result of a request to the
+// instrumentation and the logger of the index.
+type requestTrace struct {
+	index *Index
+	r     *request
+	start time.Time
+	end   func(RequestResult)
+}
+
+// startRequest notifies the instrumentation and the logger of the request sent with
+// the given headers, if any, and returns the trace to report the result of the
+// request to, or nil if neither exists.
+func (ix *Index) startRequest(r *request, header http.Header) *requestTrace {
+	if ix.instrumentation == nil && ix.logger == nil {
+		return nil
+	}
+
+	t := &requestTrace{index: ix, r: r}
+	if ix.instrumentation != nil {
+		t.end = ix.instrumentation.StartRequest(requestInfo(r))
+	}
+	ix.logRequest(r, header)
+	t.start = time.Now()
+	return t
+}
+
+// captureLimit returns how much of the response body with the
+// given status code should be kept for reporting.
+func (t *requestTrace) captureLimit(statusCode int) int {
+	if t.index.logger != nil && t.index.logVerbosity >= LogBodies {
+		return maxLoggedBodySize
+	}
+	if statusCode >= 400 {
+		return maxErrorBodySize
+	}
+	return 0
+}
+
+// finish reports the result of the request.
+func (t *requestTrace) finish(statusCode int, responseSize int, body []byte, err error) {
+	duration := time.Since(t.start)
+	if err == nil && statusCode >= 400 {
+		err = responseError(statusCode, body)
+	}
+
+	if t.end != nil {
+		t.end(RequestResult{
+			StatusCode:   statusCode,
+			ResponseSize: responseSize,
+			Duration:     duration,
+			Err:          err,
+		})
+	}
+	t.index.logResponse(t.r, statusCode, responseSize, body, duration, err)
+}
+
+// responseError returns the error in the body of a failed response.
+func responseError(statusCode int, body []byte) error {
+	var result response[json.RawMessage]
+	if json.Unmarshal(body, &result) == nil && result.Error != "" {
+		return errors.New(result.Error)
+	}
+	return fmt.Errorf("request failed with status %d", statusCode)
+}
+
+// tracedBody is a response body that reports the
+// result of the request when it is closed.
+type tracedBody struct {
+	io.ReadCloser
+	trace      *requestTrace
+	statusCode int
+	size       int
+	limit      int
+	body       []byte
+	err        error
+	once       sync.Once
+}
+
+func newTracedBody(body io.ReadCloser, trace *requestTrace, statusCode int) *tracedBody {
+	return &tracedBody{
+		ReadCloser: body,
+		trace:      trace,
+		statusCode: statusCode,
+		limit:      trace.captureLimit(statusCode),
+	}
+}
+
+func (b *tracedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.size += n
+	if len(b.body) < b.limit {
+		b.body = append(b.body, p[:min(n, b.limit-len(b.body))]...)
+	}
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.trace.finish(b.statusCode, b.size, b.body, b.err)
+	})
+	return err
+}
@@ -0,0 +1,82 @@
+package vector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingInstrumentation struct {
+	mu      sync.Mutex
+	infos   []RequestInfo
+	results []RequestResult
+}
+
+func (i *recordingInstrumentation) StartRequest(info RequestInfo) func(RequestResult) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.infos = append(i.infos, info)
+	return func(result RequestResult) {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		i.results = append(i.results, result)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case queryPath + "/ns":
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","score":1}]}`))
+		case upsertPath:
+			_, _ = w.Write([]byte(`{"result":"Success"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Invalid request","status":400}`))
+		}
+	}))
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	index := NewIndexWith(Options{
+		Url:             server.URL,
+		Token:           "token",
+		Instrumentation: instrumentation,
+	})
+
+	_, err := index.Namespace("ns").Query(Query{Vector: []float32{1, 0}, TopK: 5})
+	require.NoError(t, err)
+
+	err = index.UpsertMany([]Upsert{{Id: "a"}, {Id: "b"}})
+	require.NoError(t, err)
+
+	_, err = index.Fetch(Fetch{Ids: []string{"a"}})
+	require.Error(t, err)
+
+	require.Equal(t, 3, len(instrumentation.infos))
+	require.Equal(t, 3, len(instrumentation.results))
+
+	require.Equal(t, RequestInfo{
+		Operation:   "query",
+		Namespace:   "ns",
+		Path:        "/query/ns",
+		TopK:        5,
+		Items:       1,
+		RequestSize: instrumentation.infos[0].RequestSize,
+		Attempt:     1,
+	}, instrumentation.infos[0])
+	require.Equal(t, http.StatusOK, instrumentation.results[0].StatusCode)
+	require.Equal(t, len(`{"result":[{"id":"a","score":1}]}`), instrumentation.results[0].ResponseSize)
+	require.NoError(t, instrumentation.results[0].Err)
+
+	require.Equal(t, "upsert", instrumentation.infos[1].Operation)
+	require.Equal(t, "", instrumentation.infos[1].Namespace)
+	require.Equal(t, 2, instrumentation.infos[1].Items)
+
+	require.Equal(t, "fetch", instrumentation.infos[2].Operation)
+	require.Equal(t, http.StatusBadRequest, instrumentation.results[2].StatusCode)
+	require.EqualError(t, instrumentation.results[2].Err, "Invalid request")
+}
@@ -0,0 +1,146 @@
+// Package score implements the vector kernels and the scoring conventions
+// of Upstash Vector, shared by the vector and vecmath packages so that the
+// scores calculated on the client follow a single definition.
+//
+// The dense kernels accumulate in float64 over independent lanes, which
+// keeps the loops free of dependencies between iterations so that the
+// compiler and the CPU can pipeline or vectorize them.
+package score
+
+import (
+	"fmt"
+	"math"
+)
+
+// Names of the similarity functions of the indexes, the same
+// as the SimilarityFunction constants of the vector package.
+const (
+	Cosine     = "COSINE"
+	Euclidean  = "EUCLIDEAN"
+	DotProduct = "DOT_PRODUCT"
+)
+
+// Dense returns the score of the dense vectors with the given similarity
+// function, as it would be returned from a query to an index with that
+// similarity function:
+//
+//   - COSINE: (1 + cosine(a, b)) / 2, or 0.5 when any of the vectors is all zeros
+//   - EUCLIDEAN: 1 / (1 + squared distance(a, b))
+//   - DOT_PRODUCT: (1 + dot(a, b)) / 2
+func Dense(similarityFunction string, a []float32, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("vector dimensions do not match: %d != %d", len(a), len(b))
+	}
+
+	switch similarityFunction {
+	case Cosine:
+		return float32((1 + CosineSimilarity(a, b)) / 2), nil
+	case Euclidean:
+		return float32(1 / (1 + SquaredDistance(a, b))), nil
+	case DotProduct:
+		return float32((1 + Dot(a, b)) / 2), nil
+	default:
+		return 0, fmt.Errorf("unknown similarity function: %s", similarityFunction)
+	}
+}
+
+// Sparse returns the inner product of the sparse vectors, which is the score
+// used for the sparse vectors. Duplicate indices are treated as summed.
+// It returns an error if any of the vectors has different numbers of
+// indices and values.
+func Sparse(aIndices []int32, aValues []float32, bIndices []int32, bValues []float32) (float32, error) {
+	if len(aIndices) != len(aValues) {
+		return 0, fmt.Errorf("sparse vector has %d indices but %d values", len(aIndices), len(aValues))
+	}
+	if len(bIndices) != len(bValues) {
+		return 0, fmt.Errorf("sparse vector has %d indices but %d values", len(bIndices), len(bValues))
+	}
+	if len(aIndices) > len(bIndices) {
+		aIndices, aValues, bIndices, bValues = bIndices, bValues, aIndices, aValues
+	}
+
+	values := make(map[int32]float32, len(aIndices))
+	for i, index := range aIndices {
+		values[index] += aValues[i]
+	}
+
+	var dot float32
+	for i, index := range bIndices {
+		dot += values[index] * bValues[i]
+	}
+	return dot, nil
+}
+
+// Dot returns the inner product of the vectors,
+// which must have the same dimension.
+func Dot(a []float32, b []float32) float64 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float64
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		s0 += float64(a[i]) * float64(b[i])
+		s1 += float64(a[i+1]) * float64(b[i+1])
+		s2 += float64(a[i+2]) * float64(b[i+2])
+		s3 += float64(a[i+3]) * float64(b[i+3])
+	}
+	for i := n; i < len(a); i++ {
+		s0 += float64(a[i]) * float64(b[i])
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// SquaredDistance returns the squared euclidean distance between
+// the vectors, which must have the same dimension.
+func SquaredDistance(a []float32, b []float32) float64 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float64
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		d0 := float64(a[i]) - float64(b[i])
+		d1 := float64(a[i+1]) - float64(b[i+1])
+		d2 := float64(a[i+2]) - float64(b[i+2])
+		d3 := float64(a[i+3]) - float64(b[i+3])
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for i := n; i < len(a); i++ {
+		d := float64(a[i]) - float64(b[i])
+		s0 += d * d
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// CosineSimilarity returns the cosine of the angle between the vectors, which
+// must have the same dimension. It returns 0 when any of the vectors is all zeros.
+func CosineSimilarity(a []float32, b []float32) float64 {
+	b = b[:len(a)]
+
+	var d0, d1, na0, na1, nb0, nb1 float64
+	n := len(a) &^ 1
+	for i := 0; i < n; i += 2 {
+		x0, y0 := float64(a[i]), float64(b[i])
+		x1, y1 := float64(a[i+1]), float64(b[i+1])
+		d0 += x0 * y0
+		d1 += x1 * y1
+		na0 += x0 * x0
+		na1 += x1 * x1
+		nb0 += y0 * y0
+		nb1 += y1 * y1
+	}
+	if n < len(a) {
+		x, y := float64(a[n]), float64(b[n])
+		d0 += x * y
+		na0 += x * x
+		nb0 += y * y
+	}
+
+	normA, normB := na0+na1, nb0+nb1
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return (d0 + d1) / math.Sqrt(normA*normB)
+}
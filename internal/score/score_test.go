@@ -0,0 +1,59 @@
+package score
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDense(t *testing.T) {
+	score, err := Dense(Cosine, []float32{1, 0}, []float32{1, 0})
+	require.NoError(t, err)
+	require.Equal(t, float32(1), score)
+
+	score, err = Dense(Cosine, []float32{1, 0}, []float32{-2, 0})
+	require.NoError(t, err)
+	require.Equal(t, float32(0), score)
+
+	score, err = Dense(Cosine, []float32{1, 0}, []float32{0, 3})
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), score)
+
+	score, err = Dense(Cosine, []float32{1, 0}, []float32{0, 0})
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), score)
+
+	score, err = Dense(Euclidean, []float32{1, 0}, []float32{0, 1})
+	require.NoError(t, err)
+	require.InDelta(t, 1.0/3, score, 1e-6)
+
+	score, err = Dense(DotProduct, []float32{0.6, 0.8}, []float32{0.8, 0.6})
+	require.NoError(t, err)
+	require.InDelta(t, 0.98, score, 1e-6)
+
+	_, err = Dense(Cosine, []float32{1, 0}, []float32{1})
+	require.Error(t, err)
+
+	_, err = Dense("unknown", []float32{1}, []float32{1})
+	require.Error(t, err)
+}
+
+func TestSparse(t *testing.T) {
+	score, err := Sparse([]int32{0, 5, 3}, []float32{1, 2, 3}, []int32{3, 5}, []float32{0.5, 0.5})
+	require.NoError(t, err)
+	require.Equal(t, float32(2.5), score)
+
+	score, err = Sparse([]int32{3, 5}, []float32{0.5, 0.5}, []int32{0, 5, 3, 5}, []float32{1, 2, 3, 1})
+	require.NoError(t, err)
+	require.Equal(t, float32(3), score)
+
+	score, err = Sparse([]int32{1}, []float32{1}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, float32(0), score)
+
+	_, err = Sparse([]int32{1, 2}, []float32{1}, []int32{1}, []float32{1})
+	require.Error(t, err)
+
+	_, err = Sparse([]int32{1}, []float32{1}, []int32{1}, nil)
+	require.Error(t, err)
+}
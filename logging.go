@@ -0,0 +1,156 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LogVerbosity specifies what is logged for the requests sent to the index.
+type LogVerbosity int
+
+const (
+	// LogErrors logs only the failed requests, with the error
+	// level, along with the error bodies of the responses.
+	LogErrors LogVerbosity = iota
+
+	// LogRequests also logs the successful requests, with the
+	// debug level, along with their durations and sizes.
+	LogRequests
+
+	// LogBodies also logs the headers and the bodies of the requests
+	// and the responses, with the debug level. The token in the
+	// Authorization header is always redacted.
+	LogBodies
+)
+
+const (
+	// maxLoggedBodySize is the size of the response body
+	// that is kept for logging the bodies.
+	maxLoggedBodySize = 64 * 1024
+
+	// maxLogBodySize is the size above which the
+	// logged bodies are truncated.
+	maxLogBodySize = 8 * 1024
+
+	redacted = "[REDACTED]"
+)
+
+func (ix *Index) logRequest(r *request, header http.Header) {
+	if ix.logger == nil || ix.logVerbosity < LogBodies {
+		return
+	}
+
+	ix.logger.LogAttrs(context.Background(), slog.LevelDebug, "sending request",
+		slog.String("operation", r.operation),
+		slog.String("namespace", r.namespace),
+		slog.String("path", r.path),
+		slog.Any("headers", redactHeaders(header)),
+		slog.String("request_body", ix.logBody(r.body)),
+	)
+}
+
+func (ix *Index) logResponse(r *request, statusCode int, responseSize int, body []byte, duration time.Duration, err error) {
+	// The canceled requests, such as the hedged requests that are not
+	// used, are not failures, and are logged like the successful ones.
+	canceled := errors.Is(err, context.Canceled)
+	if ix.logger == nil || ((err == nil || canceled) && ix.logVerbosity < LogRequests) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", r.operation),
+		slog.String("namespace", r.namespace),
+		slog.String("path", r.path),
+		slog.Int("status", statusCode),
+		slog.Duration("duration", duration),
+		slog.Int("request_size", len(r.body)),
+		slog.Int("response_size", responseSize),
+	}
+
+	if canceled {
+		ix.logger.LogAttrs(context.Background(), slog.LevelDebug, "request canceled", attrs...)
+		return
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		if len(body) > 0 {
+			attrs = append(attrs, slog.String("response_body", ix.logBody(body)))
+		}
+		ix.logger.LogAttrs(context.Background(), slog.LevelError, "request failed", attrs...)
+		return
+	}
+
+	if ix.logVerbosity >= LogBodies {
+		attrs = append(attrs, slog.String("response_body", ix.logBody(body)))
+	}
+	ix.logger.LogAttrs(context.Background(), slog.LevelDebug, "request completed", attrs...)
+}
+
+// logBody returns the body to be logged, with the vectors redacted
+// unless they should be logged, and truncated if it is too long.
+func (ix *Index) logBody(body []byte) string {
+	if !ix.logVectors {
+		body = redactVectors(body)
+	}
+	if len(body) > maxLogBodySize {
+		return fmt.Sprintf("%s... (%d more bytes)", body[:maxLogBodySize], len(body)-maxLogBodySize)
+	}
+	return string(body)
+}
+
+// redactVectors returns the JSON body with the values of the dense and
+// sparse vectors redacted. Bodies that are not valid JSON, such as the
+// truncated ones, are redacted completely, as they might contain vectors.
+func redactVectors(body []byte) []byte {
+	if len(body) == 0 || !bytes.Contains(body, []byte(`"vector"`)) && !bytes.Contains(body, []byte(`"sparseVector"`)) {
+		return body
+	}
+
+	var v any
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return []byte(fmt.Sprintf("[REDACTED %d bytes]", len(body)))
+	}
+
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(fmt.Sprintf("[REDACTED %d bytes]", len(body)))
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if key == "vector" || key == "sparseVector" {
+				if value != nil {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redactValue(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	redactedHeaders := headers.Clone()
+	if redactedHeaders.Get("Authorization") != "" {
+		redactedHeaders.Set("Authorization", redacted)
+	}
+	return redactedHeaders
+}
@@ -0,0 +1,138 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == queryPath {
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","score":1,"vector":[0.123,0.456]}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"Invalid vector dimension","status":400}`))
+	}))
+	defer server.Close()
+
+	logs := func(verbosity LogVerbosity, logVectors bool) []map[string]any {
+		var buf bytes.Buffer
+		index := NewIndexWith(Options{
+			Url:          server.URL,
+			Token:        "secret-token",
+			Logger:       slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			LogVerbosity: verbosity,
+			LogVectors:   logVectors,
+		})
+
+		_, err := index.Query(Query{Vector: []float32{0.789, 0.1}, IncludeVectors: true})
+		require.NoError(t, err)
+
+		err = index.Upsert(Upsert{Id: "a", Vector: []float32{0.789}})
+		require.EqualError(t, err, "Invalid vector dimension")
+
+		require.NotContains(t, buf.String(), "secret-token")
+
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		records := logs(LogErrors, false)
+		require.Equal(t, 1, len(records))
+		require.Equal(t, "ERROR", records[0]["level"])
+		require.Equal(t, "request failed", records[0]["msg"])
+		require.Equal(t, "upsert", records[0]["operation"])
+		require.Equal(t, float64(http.StatusBadRequest), records[0]["status"])
+		require.Equal(t, "Invalid vector dimension", records[0]["error"])
+		require.Contains(t, records[0]["response_body"], "Invalid vector dimension")
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		records := logs(LogRequests, false)
+		require.Equal(t, 2, len(records))
+		require.Equal(t, "request completed", records[0]["msg"])
+		require.Equal(t, "query", records[0]["operation"])
+		require.NotContains(t, records[0], "response_body")
+	})
+
+	t.Run("bodies", func(t *testing.T) {
+		records := logs(LogBodies, false)
+		require.Equal(t, 4, len(records))
+
+		require.Equal(t, "sending request", records[0]["msg"])
+		require.Equal(t, redacted, records[0]["headers"].(map[string]any)["Authorization"].([]any)[0])
+		require.NotContains(t, records[0]["request_body"], "0.789")
+		require.Contains(t, records[0]["request_body"], redacted)
+
+		require.Equal(t, "request completed", records[1]["msg"])
+		require.NotContains(t, records[1]["response_body"], "0.123")
+	})
+
+	t.Run("vectors", func(t *testing.T) {
+		records := logs(LogBodies, true)
+		require.Contains(t, records[0]["request_body"], "0.789")
+		require.Contains(t, records[1]["response_body"], "0.123")
+	})
+}
+
+func TestRedactVectors(t *testing.T) {
+	require.Equal(t, `{"id":"a","sparseVector":"[REDACTED]","topK":1,"vector":"[REDACTED]"}`,
+		string(redactVectors([]byte(`{"id":"a","vector":[1,2],"sparseVector":{"indices":[1],"values":[1]},"topK":1}`))))
+	require.Equal(t, `[{"id":"a","vector":null}]`, string(redactVectors([]byte(`[{"id":"a","vector":null}]`))))
+	require.Equal(t, `[REDACTED 14 bytes]`, string(redactVectors([]byte(`{"vector":[1,2`))))
+	require.Equal(t, `id`, string(redactVectors([]byte(`id`))))
+}
+
+func TestLogSentHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"Success"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	index := NewIndexWith(Options{
+		Url:          server.URL,
+		Credentials:  StaticCredentials("secret-token"),
+		Logger:       slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogVerbosity: LogBodies,
+		Compression: &CompressionOptions{
+			Threshold: 1,
+		},
+		Middlewares: []Middleware{
+			func(next Handler) Handler {
+				return func(call *Call) (*Response, error) {
+					call.Header.Set("X-Source", "middleware")
+					return next(call)
+				}
+			},
+		},
+	})
+
+	err := index.Upsert(Upsert{Id: "a", Vector: []float32{0.6, 0.8}})
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "secret-token")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.Split(buf.String(), "\n")[0]), &record))
+	require.Equal(t, "sending request", record["msg"])
+
+	// The logged headers are the ones sent with the request.
+	headers := record["headers"].(map[string]any)
+	require.Equal(t, []any{redacted}, headers["Authorization"])
+	require.Equal(t, []any{"gzip"}, headers["Content-Encoding"])
+	require.Equal(t, []any{"middleware"}, headers["X-Source"])
+}
@@ -0,0 +1,128 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Call is a request to the index passing through the middlewares.
+type Call struct {
+	// Name of the operation, such as "query", "upsert" or "resumable-query-next".
+	Operation string
+
+	// Namespace of the call. It is empty for the default namespace,
+	// and for the operations that are not scoped to a namespace.
+	Namespace string
+
+	// Path of the request, relative to the URL of the index.
+	Path string
+
+	// JSON body of the request. Middlewares may replace it.
+	Body []byte
+
+	// Additional headers of the request. Middlewares may add headers,
+	// which override the default headers of the client with the same names.
+	Header http.Header
+}
+
+// Response is the parsed response of a call.
+type Response struct {
+	// HTTP status code of the response. Middlewares that short-circuit
+	// the calls may leave it as 0.
+	StatusCode int
+
+	// JSON result of the call, which is decoded into the return
+	// values of the client methods.
+	Result json.RawMessage
+
+	// Error message of the failed calls, which is returned
+	// as an error from the client methods.
+	Error string
+}
+
+// Handler sends a call and returns its response. The error is only returned
+// when a response cannot be received, and the errors returned from the
+// server are reported with Response.Error.
+type Handler func(call *Call) (*Response, error)
+
+// Middleware wraps a handler, to observe or mutate the calls and their
+// responses, or to short-circuit the calls by not calling the next handler.
+//
+//	func countCalls(counts map[string]int) vector.Middleware {
+//		return func(next vector.Handler) vector.Handler {
+//			return func(call *vector.Call) (*vector.Response, error) {
+//				counts[call.Operation]++
+//				return next(call)
+//			}
+//		}
+//	}
+//
+// Middlewares can be called concurrently, and the responses are decoded
+// only after they return, so the streaming iterators read the whole responses
+// into memory when middlewares are used.
+type Middleware func(next Handler) Handler
+
+// openWithMiddlewares sends the request through the middlewares, and returns
+// the response, encoded back into the response body format.
+func (ix *Index) openWithMiddlewares(r *request) (body io.ReadCloser, err error) {
+	// The buffers of the calls are not reused, as the
+	// middlewares might keep or resend the calls.
+	r.release = nil
+
+	handler := ix.httpHandler(r)
+	for i := len(ix.middlewares) - 1; i >= 0; i-- {
+		handler = ix.middlewares[i](handler)
+	}
+
+	res, err := handler(&Call{
+		Operation: r.operation,
+		Namespace: r.namespace,
+		Path:      r.path,
+		Body:      r.body,
+		Header:    http.Header{},
+	})
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(response[json.RawMessage]{
+		Result: res.Result,
+		Error:  res.Error,
+		Status: res.StatusCode,
+	})
+	if err != nil {
+		return
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// httpHandler returns the handler that sends the calls to the index.
+func (ix *Index) httpHandler(r *request) Handler {
+	return func(call *Call) (res *Response, err error) {
+		cr := newRequest(call.Path, call.Body, r.payload)
+		cr.header = call.Header
+
+		body, statusCode, err := ix.roundTrip(cr)
+		if err != nil {
+			return
+		}
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return
+		}
+
+		var result response[json.RawMessage]
+		if err = json.Unmarshal(data, &result); err != nil {
+			return
+		}
+		return &Response{
+			StatusCode: statusCode,
+			Result:     result.Result,
+			Error:      result.Error,
+		}, nil
+	}
+}
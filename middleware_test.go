@@ -0,0 +1,111 @@
+package vector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewares(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+
+		require.Equal(t, "middleware", r.Header.Get("X-Source"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case queryPath + "/ns":
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","score":1}]}`))
+		case fetchPath:
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","vector":[0.1,0.2]},{"id":"b"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Invalid vector dimension","status":400}`))
+		}
+	}))
+	defer server.Close()
+
+	var order []string
+	var calls []*Call
+	var responses []*Response
+	record := func(next Handler) Handler {
+		return func(call *Call) (*Response, error) {
+			order = append(order, "record")
+			calls = append(calls, call)
+			res, err := next(call)
+			responses = append(responses, res)
+			return res, err
+		}
+	}
+	header := func(next Handler) Handler {
+		return func(call *Call) (*Response, error) {
+			order = append(order, "header")
+			call.Header.Set("X-Source", "middleware")
+			return next(call)
+		}
+	}
+	stub := func(next Handler) Handler {
+		return func(call *Call) (*Response, error) {
+			if call.Operation == "info" {
+				return &Response{Result: json.RawMessage(`{"vectorCount":42}`)}, nil
+			}
+			if call.Operation == "delete" {
+				call.Body = []byte(`["replaced"]`)
+			}
+			return next(call)
+		}
+	}
+
+	index := NewIndexWith(Options{
+		Url:         server.URL,
+		Token:       "token",
+		Middlewares: []Middleware{record, header, stub},
+	})
+
+	scores, err := index.Namespace("ns").Query(Query{TopK: 1})
+	require.NoError(t, err)
+	require.Equal(t, []VectorScore{{Id: "a", Score: 1}}, scores)
+	require.Equal(t, []string{"record", "header"}, order)
+	require.Equal(t, "query", calls[0].Operation)
+	require.Equal(t, "ns", calls[0].Namespace)
+	require.Equal(t, queryPath+"/ns", calls[0].Path)
+	require.JSONEq(t, `{"topK":1}`, string(calls[0].Body))
+	require.Equal(t, http.StatusOK, responses[0].StatusCode)
+	require.JSONEq(t, `[{"id":"a","score":1}]`, string(responses[0].Result))
+
+	t.Run("short circuit", func(t *testing.T) {
+		info, err := index.Info()
+		require.NoError(t, err)
+		require.Equal(t, 42, info.VectorCount)
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		_, err := index.Delete("a")
+		require.EqualError(t, err, "Invalid vector dimension")
+		require.Equal(t, `["replaced"]`, bodies[len(bodies)-1])
+		require.Equal(t, "Invalid vector dimension", responses[len(responses)-1].Error)
+		require.Equal(t, http.StatusBadRequest, responses[len(responses)-1].StatusCode)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		it := index.FetchIterator(Fetch{Ids: []string{"a", "b"}, IncludeVectors: true})
+		defer it.Close()
+
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Vector().Id)
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{"a", "b"}, ids)
+	})
+}
@@ -0,0 +1,550 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// MigrationOptions configures the behaviour of a MigrationIndex.
+type MigrationOptions struct {
+	// Whether to run the queries also on the secondary index
+	// and compare the results with the ones of the primary index.
+	// The comparisons are reported to OnShadowQuery, without
+	// which the queries are not run on the secondary index.
+	ShadowReads bool
+
+	// Optional function to convert the dense and sparse vectors
+	// written to the primary index into the ones written to the secondary
+	// index, such as when the dimension of the secondary index is different.
+	// It is also applied to the query vectors of the shadow reads.
+	Rewrite func(vector []float32, sparseVector *SparseVector) ([]float32, *SparseVector, error)
+
+	// Optional function to call when a write to the secondary index fails.
+	// When it is not provided, the failed writes to the secondary index are
+	// reported to the callers as *MirrorError.
+	OnMirrorError func(err *MirrorError)
+
+	// Optional function to call with the comparison of the results
+	// of the shadow reads.
+	OnShadowQuery func(report ShadowQueryReport)
+}
+
+// MirrorError is the error of a write that succeeded on the primary
+// index but failed on the secondary index.
+type MirrorError struct {
+	// Name of the failed operation.
+	Op string
+
+	// Namespace of the failed operation.
+	Namespace string
+
+	// The error returned from the secondary index.
+	Err error
+}
+
+func (e *MirrorError) Error() string {
+	return fmt.Sprintf("mirroring %s to secondary index failed: %v", e.Op, e.Err)
+}
+
+func (e *MirrorError) Unwrap() error {
+	return e.Err
+}
+
+// ShadowQueryReport is the comparison of the results of a query
+// on the primary and secondary indexes.
+type ShadowQueryReport struct {
+	// Namespace of the query.
+	Namespace string
+
+	// Results of the query on the primary index.
+	Primary []VectorScore
+
+	// Results of the query on the secondary index.
+	Secondary []VectorScore
+
+	// The error returned from the secondary index, if any.
+	// The other comparison fields are not set when it is not nil.
+	SecondaryErr error
+
+	// Fraction of the vectors in the primary results that are
+	// also in the secondary results, where k is the number of the
+	// primary results. It is 1 when both of the results are empty.
+	RecallAtK float64
+
+	// Spearman's rank correlation coefficient of the vectors that
+	// are present in both of the results. It is between -1 and 1, and
+	// it is NaN when there are fewer than two common vectors.
+	RankCorrelation float64
+
+	// Time it took to get the results from the primary index.
+	PrimaryLatency time.Duration
+
+	// Time it took to get the results from the secondary index.
+	SecondaryLatency time.Duration
+}
+
+// MigrationIndex is a client for migrating from a primary index to
+// a secondary index.
+//
+// Writes are sent to both of the indexes, and reads are served from
+// the primary index. Optionally, queries are also run on the secondary
+// index in the background and the results of both indexes are compared.
+type MigrationIndex struct {
+	primary   *Index
+	secondary *Index
+	options   MigrationOptions
+	shadows   sync.WaitGroup
+}
+
+// NewMigrationIndex returns a client that mirrors the writes to the primary
+// index to the secondary index.
+func NewMigrationIndex(primary *Index, secondary *Index, options MigrationOptions) *MigrationIndex {
+	return &MigrationIndex{
+		primary:   primary,
+		secondary: secondary,
+		options:   options,
+	}
+}
+
+// Primary returns the client of the primary index.
+func (m *MigrationIndex) Primary() *Index {
+	return m.primary
+}
+
+// Secondary returns the client of the secondary index.
+func (m *MigrationIndex) Secondary() *Index {
+	return m.secondary
+}
+
+// Wait waits for the shadow reads running in the background to complete.
+func (m *MigrationIndex) Wait() {
+	m.shadows.Wait()
+}
+
+// MigrationNamespace is a client for a namespace of a MigrationIndex.
+type MigrationNamespace struct {
+	index *MigrationIndex
+	ns    string
+}
+
+// Namespace returns a new client associated with the given namespace.
+func (m *MigrationIndex) Namespace(namespace string) *MigrationNamespace {
+	return &MigrationNamespace{
+		index: m,
+		ns:    namespace,
+	}
+}
+
+// Upsert updates or inserts a vector to the default namespace of both of the indexes.
+// Additional metadata can also be provided while upserting the vector.
+func (m *MigrationIndex) Upsert(u Upsert) (err error) {
+	return m.upsertManyInternal([]Upsert{u}, defaultNamespace)
+}
+
+// UpsertMany updates or inserts some vectors to the default namespace of both of the indexes.
+// Additional metadata can also be provided for each vector.
+func (m *MigrationIndex) UpsertMany(u []Upsert) (err error) {
+	return m.upsertManyInternal(u, defaultNamespace)
+}
+
+// UpsertData updates or inserts a vector to the default namespace of both of the indexes
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided while upserting the vector.
+func (m *MigrationIndex) UpsertData(u UpsertData) (err error) {
+	return m.upsertDataManyInternal([]UpsertData{u}, defaultNamespace)
+}
+
+// UpsertDataMany updates or inserts some vectors to the default namespace of both of the indexes
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided for each vector.
+func (m *MigrationIndex) UpsertDataMany(u []UpsertData) (err error) {
+	return m.upsertDataManyInternal(u, defaultNamespace)
+}
+
+// Update updates a vector value, data, or metadata for the given id
+// for the default namespace of both of the indexes and reports whether
+// the vector is updated in the primary index.
+func (m *MigrationIndex) Update(u Update) (ok bool, err error) {
+	return m.updateInternal(u, defaultNamespace)
+}
+
+// Delete deletes the vector with the given id in the default namespace of both of the indexes
+// and reports whether the vector is deleted from the primary index.
+func (m *MigrationIndex) Delete(id string) (ok bool, err error) {
+	return m.deleteInternal(id, defaultNamespace)
+}
+
+// DeleteMany deletes the vectors with the given ids in the default namespace of both of the indexes
+// and reports how many of them are deleted from the primary index.
+func (m *MigrationIndex) DeleteMany(ids []string) (count int, err error) {
+	return m.deleteManyInternal(ids, defaultNamespace)
+}
+
+// Reset deletes all the vectors in the default namespace of both of the indexes.
+func (m *MigrationIndex) Reset() (err error) {
+	return m.resetInternal(defaultNamespace)
+}
+
+// Query returns the result of the query for the given vector in the default namespace
+// of the primary index. When shadow reads are enabled, the query is also run on
+// the secondary index in the background.
+func (m *MigrationIndex) Query(q Query) (scores []VectorScore, err error) {
+	return m.queryInternal(q, defaultNamespace)
+}
+
+// QueryData returns the result of the query for the given data in the default namespace
+// of the primary index. When shadow reads are enabled, the query is also run on
+// the secondary index in the background.
+func (m *MigrationIndex) QueryData(q QueryData) (scores []VectorScore, err error) {
+	return m.queryDataInternal(q, defaultNamespace)
+}
+
+// Fetch fetches one or more vectors in the default namespace of the primary index.
+func (m *MigrationIndex) Fetch(f Fetch) (vectors []Vector, err error) {
+	return m.primary.fetchInternal(f, defaultNamespace)
+}
+
+// Range returns a range of vectors in the default namespace of the primary index.
+func (m *MigrationIndex) Range(r Range) (vectors RangeVectors, err error) {
+	return m.primary.rangeInternal(r, defaultNamespace)
+}
+
+// Info returns some information about the primary index.
+func (m *MigrationIndex) Info() (info IndexInfo, err error) {
+	return m.primary.Info()
+}
+
+// ListNamespaces returns the list of names of namespaces of the primary index.
+func (m *MigrationIndex) ListNamespaces() (namespaces []string, err error) {
+	return m.primary.ListNamespaces()
+}
+
+// Upsert updates or inserts a vector to the namespace of both of the indexes.
+// Additional metadata can also be provided while upserting the vector.
+func (ns *MigrationNamespace) Upsert(u Upsert) (err error) {
+	return ns.index.upsertManyInternal([]Upsert{u}, ns.ns)
+}
+
+// UpsertMany updates or inserts some vectors to the namespace of both of the indexes.
+// Additional metadata can also be provided for each vector.
+func (ns *MigrationNamespace) UpsertMany(u []Upsert) (err error) {
+	return ns.index.upsertManyInternal(u, ns.ns)
+}
+
+// UpsertData updates or inserts a vector to the namespace of both of the indexes
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided while upserting the vector.
+func (ns *MigrationNamespace) UpsertData(u UpsertData) (err error) {
+	return ns.index.upsertDataManyInternal([]UpsertData{u}, ns.ns)
+}
+
+// UpsertDataMany updates or inserts some vectors to the namespace of both of the indexes
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided for each vector.
+func (ns *MigrationNamespace) UpsertDataMany(u []UpsertData) (err error) {
+	return ns.index.upsertDataManyInternal(u, ns.ns)
+}
+
+// Update updates a vector value, data, or metadata for the given id
+// for the namespace of both of the indexes and reports whether
+// the vector is updated in the primary index.
+func (ns *MigrationNamespace) Update(u Update) (ok bool, err error) {
+	return ns.index.updateInternal(u, ns.ns)
+}
+
+// Delete deletes the vector with the given id in the namespace of both of the indexes
+// and reports whether the vector is deleted from the primary index.
+func (ns *MigrationNamespace) Delete(id string) (ok bool, err error) {
+	return ns.index.deleteInternal(id, ns.ns)
+}
+
+// DeleteMany deletes the vectors with the given ids in the namespace of both of the indexes
+// and reports how many of them are deleted from the primary index.
+func (ns *MigrationNamespace) DeleteMany(ids []string) (count int, err error) {
+	return ns.index.deleteManyInternal(ids, ns.ns)
+}
+
+// Reset deletes all the vectors in the namespace of both of the indexes.
+func (ns *MigrationNamespace) Reset() (err error) {
+	return ns.index.resetInternal(ns.ns)
+}
+
+// DeleteNamespace deletes the namespace in both of the indexes.
+func (ns *MigrationNamespace) DeleteNamespace() (err error) {
+	return ns.index.mirror("delete-namespace", ns.ns, func() error {
+		return ns.index.primary.Namespace(ns.ns).DeleteNamespace()
+	}, func() error {
+		return ns.index.secondary.Namespace(ns.ns).DeleteNamespace()
+	})
+}
+
+// Query returns the result of the query for the given vector in the namespace
+// of the primary index. When shadow reads are enabled, the query is also run on
+// the secondary index in the background.
+func (ns *MigrationNamespace) Query(q Query) (scores []VectorScore, err error) {
+	return ns.index.queryInternal(q, ns.ns)
+}
+
+// QueryData returns the result of the query for the given data in the namespace
+// of the primary index. When shadow reads are enabled, the query is also run on
+// the secondary index in the background.
+func (ns *MigrationNamespace) QueryData(q QueryData) (scores []VectorScore, err error) {
+	return ns.index.queryDataInternal(q, ns.ns)
+}
+
+// Fetch fetches one or more vectors in the namespace of the primary index.
+func (ns *MigrationNamespace) Fetch(f Fetch) (vectors []Vector, err error) {
+	return ns.index.primary.fetchInternal(f, ns.ns)
+}
+
+// Range returns a range of vectors in the namespace of the primary index.
+func (ns *MigrationNamespace) Range(r Range) (vectors RangeVectors, err error) {
+	return ns.index.primary.rangeInternal(r, ns.ns)
+}
+
+func (m *MigrationIndex) upsertManyInternal(u []Upsert, ns string) (err error) {
+	return m.mirror("upsert", ns, func() error {
+		return m.primary.upsertManyInternal(u, ns)
+	}, func() (err error) {
+		su := u
+		if m.options.Rewrite != nil {
+			su = make([]Upsert, len(u))
+			for i, v := range u {
+				if v.Vector, v.SparseVector, err = m.options.Rewrite(v.Vector, v.SparseVector); err != nil {
+					return
+				}
+				su[i] = v
+			}
+		}
+		return m.secondary.upsertManyInternal(su, ns)
+	})
+}
+
+func (m *MigrationIndex) upsertDataManyInternal(u []UpsertData, ns string) (err error) {
+	return m.mirror("upsert-data", ns, func() error {
+		return m.primary.upsertDataManyInternal(u, ns)
+	}, func() error {
+		return m.secondary.upsertDataManyInternal(u, ns)
+	})
+}
+
+func (m *MigrationIndex) updateInternal(u Update, ns string) (ok bool, err error) {
+	err = m.mirror("update", ns, func() (err error) {
+		ok, err = m.primary.updateInternal(u, ns)
+		return
+	}, func() (err error) {
+		su := u
+		if m.options.Rewrite != nil && (su.Vector != nil || su.SparseVector != nil) {
+			if su.Vector, su.SparseVector, err = m.options.Rewrite(su.Vector, su.SparseVector); err != nil {
+				return
+			}
+		}
+		_, err = m.secondary.updateInternal(su, ns)
+		return
+	})
+	return
+}
+
+func (m *MigrationIndex) deleteInternal(id string, ns string) (ok bool, err error) {
+	err = m.mirror("delete", ns, func() (err error) {
+		ok, err = m.primary.deleteInternal(id, ns)
+		return
+	}, func() (err error) {
+		_, err = m.secondary.deleteInternal(id, ns)
+		return
+	})
+	return
+}
+
+func (m *MigrationIndex) deleteManyInternal(ids []string, ns string) (count int, err error) {
+	err = m.mirror("delete", ns, func() (err error) {
+		count, err = m.primary.deleteManyInternal(ids, ns)
+		return
+	}, func() (err error) {
+		_, err = m.secondary.deleteManyInternal(ids, ns)
+		return
+	})
+	return
+}
+
+func (m *MigrationIndex) resetInternal(ns string) (err error) {
+	return m.mirror("reset", ns, func() error {
+		return m.primary.resetInternal(ns)
+	}, func() error {
+		return m.secondary.resetInternal(ns)
+	})
+}
+
+func (m *MigrationIndex) queryInternal(q Query, ns string) (scores []VectorScore, err error) {
+	return m.shadow(ns, func() ([]VectorScore, error) {
+		return m.primary.queryInternal(q, ns)
+	}, func() (scores []VectorScore, err error) {
+		sq := q
+		if m.options.Rewrite != nil {
+			if sq.Vector, sq.SparseVector, err = m.options.Rewrite(sq.Vector, sq.SparseVector); err != nil {
+				return
+			}
+		}
+		return m.secondary.queryInternal(sq, ns)
+	})
+}
+
+func (m *MigrationIndex) queryDataInternal(q QueryData, ns string) (scores []VectorScore, err error) {
+	return m.shadow(ns, func() ([]VectorScore, error) {
+		return m.primary.queryDataInternal(q, ns)
+	}, func() ([]VectorScore, error) {
+		return m.secondary.queryDataInternal(q, ns)
+	})
+}
+
+// mirror runs the write on the primary index, and then on the secondary
+// index if it succeeds, so that the secondary index is not changed by the
+// writes rejected by the primary index. The error of the primary index is
+// returned as it is, and the error of the secondary index is reported as
+// *MirrorError.
+func (m *MigrationIndex) mirror(op string, ns string, primary func() error, secondary func() error) error {
+	if err := primary(); err != nil {
+		return err
+	}
+
+	if err := secondary(); err != nil {
+		mirrorErr := &MirrorError{Op: op, Namespace: ns, Err: err}
+		if m.options.OnMirrorError == nil {
+			return mirrorErr
+		}
+		m.options.OnMirrorError(mirrorErr)
+	}
+	return nil
+}
+
+// shadow runs the query on the primary index, and also on the secondary
+// index in the background when the shadow reads are enabled. The queries
+// are not sent to the secondary index when there is no OnShadowQuery to
+// report their results to.
+func (m *MigrationIndex) shadow(ns string, primary func() ([]VectorScore, error), secondary func() ([]VectorScore, error)) ([]VectorScore, error) {
+	if !m.options.ShadowReads || m.options.OnShadowQuery == nil {
+		return primary()
+	}
+
+	type result struct {
+		scores  []VectorScore
+		err     error
+		latency time.Duration
+	}
+
+	secondaryResult := make(chan result, 1)
+	m.shadows.Add(1)
+	go func() {
+		start := time.Now()
+		scores, err := secondary()
+		secondaryResult <- result{scores: scores, err: err, latency: time.Since(start)}
+	}()
+
+	start := time.Now()
+	scores, err := primary()
+	primaryLatency := time.Since(start)
+	if err != nil {
+		// nothing to compare against, the secondary result is dropped.
+		go func() {
+			defer m.shadows.Done()
+			<-secondaryResult
+		}()
+		return scores, err
+	}
+
+	// The scores returned to the caller might be modified
+	// while the report is created, so a copy is reported.
+	primaryScores := cloneVectorScores(scores)
+
+	go func() {
+		defer m.shadows.Done()
+		res := <-secondaryResult
+
+		report := ShadowQueryReport{
+			Namespace:        ns,
+			Primary:          primaryScores,
+			Secondary:        res.scores,
+			SecondaryErr:     res.err,
+			PrimaryLatency:   primaryLatency,
+			SecondaryLatency: res.latency,
+		}
+		if res.err == nil {
+			report.RecallAtK = recallAtK(primaryScores, res.scores)
+			report.RankCorrelation = rankCorrelation(primaryScores, res.scores)
+		}
+		m.options.OnShadowQuery(report)
+	}()
+	return scores, err
+}
+
+// recallAtK returns the fraction of the expected vectors that
+// are also present in the actual vectors.
+func recallAtK(expected []VectorScore, actual []VectorScore) float64 {
+	if len(expected) == 0 {
+		if len(actual) == 0 {
+			return 1
+		}
+		return 0
+	}
+
+	ids := make(map[string]struct{}, len(actual))
+	for _, score := range actual {
+		ids[score.Id] = struct{}{}
+	}
+
+	found := 0
+	for _, score := range expected {
+		if _, ok := ids[score.Id]; ok {
+			found++
+		}
+	}
+	return float64(found) / float64(len(expected))
+}
+
+// rankCorrelation returns the Spearman's rank correlation coefficient
+// of the vectors that are present in both of the lists.
+func rankCorrelation(a []VectorScore, b []VectorScore) float64 {
+	bPositions := make(map[string]int, len(b))
+	for i, score := range b {
+		if _, ok := bPositions[score.Id]; !ok {
+			bPositions[score.Id] = i
+		}
+	}
+
+	// positions of the common vectors in b, in the order of a
+	var common []int
+	seen := make(map[string]struct{}, len(a))
+	for _, score := range a {
+		if _, ok := seen[score.Id]; ok {
+			continue
+		}
+		seen[score.Id] = struct{}{}
+		if pos, ok := bPositions[score.Id]; ok {
+			common = append(common, pos)
+		}
+	}
+
+	n := len(common)
+	if n < 2 {
+		return math.NaN()
+	}
+
+	// rank of each common vector in b, among the common vectors
+	ranks := make([]int, n)
+	for i, pos := range common {
+		for _, other := range common {
+			if other < pos {
+				ranks[i]++
+			}
+		}
+	}
+
+	var sum float64
+	for i, rank := range ranks {
+		d := float64(i - rank)
+		sum += d * d
+	}
+	return 1 - 6*sum/float64(n*(n*n-1))
+}
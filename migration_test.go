@@ -0,0 +1,189 @@
+package vector
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecallAtK(t *testing.T) {
+	scores := func(ids ...string) []VectorScore {
+		s := make([]VectorScore, len(ids))
+		for i, id := range ids {
+			s[i] = VectorScore{Id: id}
+		}
+		return s
+	}
+
+	require.Equal(t, 1.0, recallAtK(nil, nil))
+	require.Equal(t, 0.0, recallAtK(nil, scores("a")))
+	require.Equal(t, 1.0, recallAtK(scores("a", "b"), scores("b", "a")))
+	require.Equal(t, 0.5, recallAtK(scores("a", "b"), scores("b", "c")))
+}
+
+func TestRankCorrelation(t *testing.T) {
+	scores := func(ids ...string) []VectorScore {
+		s := make([]VectorScore, len(ids))
+		for i, id := range ids {
+			s[i] = VectorScore{Id: id}
+		}
+		return s
+	}
+
+	require.Equal(t, 1.0, rankCorrelation(scores("a", "b", "c"), scores("a", "b", "c")))
+	require.Equal(t, -1.0, rankCorrelation(scores("a", "b", "c"), scores("c", "b", "a")))
+	require.Equal(t, 1.0, rankCorrelation(scores("a", "x", "b", "c"), scores("a", "y", "b", "c")))
+	require.InDelta(t, 0.5, rankCorrelation(scores("a", "b", "c"), scores("b", "a", "c")), 1e-9)
+	require.True(t, math.IsNaN(rankCorrelation(scores("a"), scores("a"))))
+}
+
+func TestMirror(t *testing.T) {
+	_, failing := newFakeShard(t)
+	failing.readOnly = true
+	secondaryShard, secondary := newFakeShard(t)
+
+	var mirrorErrs []*MirrorError
+	index := NewMigrationIndex(failing, secondary, MigrationOptions{
+		OnMirrorError: func(err *MirrorError) {
+			mirrorErrs = append(mirrorErrs, err)
+		},
+	})
+
+	err := index.Upsert(Upsert{Id: "a", Vector: []float32{1, 0}})
+	var readOnlyErr *ReadOnlyError
+	require.True(t, errors.As(err, &readOnlyErr))
+	require.Empty(t, secondaryShard.ids(defaultNamespace))
+	require.Empty(t, mirrorErrs)
+}
+
+func TestShadowQueryReportCopy(t *testing.T) {
+	primaryShard, primary := newFakeShard(t)
+	_, secondary := newFakeShard(t)
+	require.NoError(t, primary.Upsert(Upsert{Id: "a", Vector: []float32{1, 0}}))
+	require.NoError(t, primary.Upsert(Upsert{Id: "b", Vector: []float32{0, 1}}))
+	require.Equal(t, []string{"a", "b"}, primaryShard.ids(defaultNamespace))
+
+	reports := make(chan ShadowQueryReport, 1)
+	index := NewMigrationIndex(primary, secondary, MigrationOptions{
+		ShadowReads: true,
+		OnShadowQuery: func(report ShadowQueryReport) {
+			reports <- report
+		},
+	})
+
+	scores, err := index.Query(Query{Vector: []float32{1, 0.5}, TopK: 2})
+	require.NoError(t, err)
+	require.Equal(t, "a", scores[0].Id)
+
+	// Modifying the results does not affect the report.
+	scores[0], scores[1] = scores[1], scores[0]
+	scores[0].Id = "modified"
+
+	report := <-reports
+	index.Wait()
+	require.Equal(t, "a", report.Primary[0].Id)
+	require.Equal(t, "b", report.Primary[1].Id)
+}
+
+func TestShadowReadsWithoutCallback(t *testing.T) {
+	_, primary := newFakeShard(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	// The queries are not sent to the secondary index without a report to compare them for.
+	index := NewMigrationIndex(primary, NewIndex(server.URL, "token"), MigrationOptions{
+		ShadowReads: true,
+	})
+	_, err := index.Query(Query{Vector: []float32{1, 0}, TopK: 1})
+	require.NoError(t, err)
+	index.Wait()
+	require.Equal(t, int32(0), requests.Load())
+}
+
+func TestMigrationIndex(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			primary, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			secondary, err := newTestClient(testClientTypeHybrid, ns)
+			require.NoError(t, err)
+
+			reports := make(chan ShadowQueryReport, 1)
+			index := NewMigrationIndex(primary.index, secondary.index, MigrationOptions{
+				ShadowReads: true,
+				Rewrite: func(vector []float32, sparseVector *SparseVector) ([]float32, *SparseVector, error) {
+					return vector, &SparseVector{Indices: []int32{0}, Values: []float32{1}}, nil
+				},
+				OnShadowQuery: func(report ShadowQueryReport) {
+					reports <- report
+				},
+			})
+			namespace := index.Namespace(ns)
+
+			id0 := randomString()
+			id1 := randomString()
+			err = namespace.UpsertMany([]Upsert{
+				{Id: id0, Vector: []float32{0.6, 0.8}},
+				{Id: id1, Vector: []float32{0.8, 0.6}},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := secondary.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			t.Run("mirrored writes", func(t *testing.T) {
+				vectors, err := secondary.Fetch(Fetch{
+					Ids:            []string{id0, id1},
+					IncludeVectors: true,
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, len(vectors))
+				require.Equal(t, []float32{0.6, 0.8}, vectors[0].Vector)
+				require.Equal(t, &SparseVector{Indices: []int32{0}, Values: []float32{1}}, vectors[0].SparseVector)
+			})
+
+			t.Run("shadow query", func(t *testing.T) {
+				scores, err := namespace.Query(Query{
+					Vector: []float32{0.6, 0.8},
+					TopK:   2,
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, len(scores))
+				require.Equal(t, id0, scores[0].Id)
+
+				index.Wait()
+				report := <-reports
+				require.NoError(t, report.SecondaryErr)
+				require.Equal(t, ns, report.Namespace)
+				require.Equal(t, 1.0, report.RecallAtK)
+				require.Equal(t, 2, len(report.Secondary))
+			})
+
+			t.Run("mirrored deletes", func(t *testing.T) {
+				count, err := namespace.DeleteMany([]string{id0, id1})
+				require.NoError(t, err)
+				require.Equal(t, 2, count)
+
+				vectors, err := secondary.Fetch(Fetch{Ids: []string{id0, id1}})
+				require.NoError(t, err)
+				for _, v := range vectors {
+					require.Empty(t, v.Id)
+				}
+			})
+		})
+	}
+}
@@ -28,6 +28,8 @@ func (ix *Index) ListNamespaces() (namespaces []string, err error) {
 
 // DeleteNamespace deletes the given namespace of index if it exists.
 func (ns *Namespace) DeleteNamespace() error {
+	defer ns.index.invalidateQueryCache(ns.ns)
+
 	_, err := ns.index.sendBytes(buildPath(deleteNamespacePath, ns.ns), nil)
 	return err
 }
@@ -65,6 +67,12 @@ func (ns *Namespace) Fetch(f Fetch) (vectors []Vector, err error) {
 	return ns.index.fetchInternal(f, ns.ns)
 }
 
+// FetchIterator returns an iterator over the vectors in the namespace with the ids passed into f.
+// The vectors are decoded as they are read from the response. See Index.FetchIterator for details.
+func (ns *Namespace) FetchIterator(f Fetch) *VectorIterator {
+	return ns.index.fetchIteratorInternal(f, ns.ns)
+}
+
 // QueryData returns the result of the query for the given data by converting it to an embedding on the server.
 // When q.TopK is specified, the result will contain at most q.TopK many vectors.
 // The returned list will contain vectors sorted in descending order of score,
@@ -95,6 +103,13 @@ func (ns *Namespace) Range(r Range) (vectors RangeVectors, err error) {
 	return ns.index.rangeInternal(r, ns.ns)
 }
 
+// RangeIterator returns an iterator over the vectors in the namespace, starting with
+// r.Cursor (inclusive), until the end of the vectors in the namespace.
+// The vectors are decoded as they are read from the responses. See Index.RangeIterator for details.
+func (ns *Namespace) RangeIterator(r Range) *RangeIterator {
+	return ns.index.rangeIteratorInternal(r, ns.ns)
+}
+
 // Delete deletes the vector with the given id in the namespace and reports whether the vector is deleted.
 // If a vector with the given id is not found, Delete returns false.
 func (ns *Namespace) Delete(id string) (ok bool, err error) {
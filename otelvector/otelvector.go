@@ -0,0 +1,162 @@
+// Package otelvector instruments the Upstash Vector client with OpenTelemetry.
+//
+// Each request sent to the index is traced with a client span, and its
+// duration, request and response sizes, and errors are recorded as metrics.
+//
+//	index := vector.NewIndexWith(vector.Options{
+//		Url:             "<UPSTASH_VECTOR_REST_URL>",
+//		Token:           "<UPSTASH_VECTOR_REST_TOKEN>",
+//		Instrumentation: otelvector.New(otelvector.Options{}),
+//	})
+//
+// The client does not accept a context, so the spans are started
+// as root spans, unless Options.Context is provided.
+package otelvector
+
+import (
+	"context"
+
+	"github.com/upstash/vector-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ScopeName is the instrumentation scope name of the tracer and the meter.
+	ScopeName = "github.com/upstash/vector-go/otelvector"
+
+	dbSystem = "upstash_vector"
+)
+
+// Attribute keys of the spans and the metrics.
+const (
+	OperationKey   = attribute.Key("db.operation.name")
+	NamespaceKey   = attribute.Key("db.namespace")
+	SystemKey      = attribute.Key("db.system")
+	TopKKey        = attribute.Key("upstash.vector.top_k")
+	ItemsKey       = attribute.Key("upstash.vector.items")
+	RequestSizeKey = attribute.Key("upstash.vector.request.size")
+	AttemptKey     = attribute.Key("upstash.vector.attempt")
+	StatusCodeKey  = attribute.Key("http.response.status_code")
+)
+
+// Options configures the instrumentation.
+type Options struct {
+	// Tracer provider used to create the spans.
+	// If not provided, the global tracer provider is used.
+	TracerProvider trace.TracerProvider
+
+	// Meter provider used to record the metrics.
+	// If not provided, the global meter provider is used.
+	MeterProvider metric.MeterProvider
+
+	// Optional function returning the context the spans are started
+	// with, such as a context holding the span of the current request
+	// of a single request scoped client.
+	Context func() context.Context
+}
+
+type instrumentation struct {
+	tracer  trace.Tracer
+	context func() context.Context
+
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+	errors       metric.Int64Counter
+}
+
+// New returns an instrumentation to be used in vector.Options.
+func New(options Options) vector.Instrumentation {
+	if options.TracerProvider == nil {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+	if options.MeterProvider == nil {
+		options.MeterProvider = otel.GetMeterProvider()
+	}
+	if options.Context == nil {
+		options.Context = context.Background
+	}
+
+	meter := options.MeterProvider.Meter(ScopeName)
+	i := &instrumentation{
+		tracer:  options.TracerProvider.Tracer(ScopeName),
+		context: options.Context,
+	}
+
+	var err error
+	if i.duration, err = meter.Float64Histogram(
+		"upstash.vector.request.duration",
+		metric.WithDescription("Duration of the requests sent to the index."),
+		metric.WithUnit("s"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if i.requestSize, err = meter.Int64Histogram(
+		"upstash.vector.request.size",
+		metric.WithDescription("Size of the request bodies before compression."),
+		metric.WithUnit("By"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if i.responseSize, err = meter.Int64Histogram(
+		"upstash.vector.response.size",
+		metric.WithDescription("Size of the response bodies after decompression."),
+		metric.WithUnit("By"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if i.errors, err = meter.Int64Counter(
+		"upstash.vector.request.errors",
+		metric.WithDescription("Number of the failed requests."),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	return i
+}
+
+func (i *instrumentation) StartRequest(info vector.RequestInfo) func(vector.RequestResult) {
+	attrs := []attribute.KeyValue{
+		SystemKey.String(dbSystem),
+		OperationKey.String(info.Operation),
+		NamespaceKey.String(info.Namespace),
+	}
+
+	ctx, span := i.tracer.Start(i.context(), "vector."+info.Operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(
+			TopKKey.Int(info.TopK),
+			ItemsKey.Int(info.Items),
+			RequestSizeKey.Int(info.RequestSize),
+			AttemptKey.Int(info.Attempt),
+		),
+	)
+
+	return func(result vector.RequestResult) {
+		if result.StatusCode != 0 {
+			span.SetAttributes(StatusCodeKey.Int(result.StatusCode))
+		}
+		if result.Err != nil {
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, result.Err.Error())
+		}
+		span.End()
+
+		if i.duration == nil || i.requestSize == nil || i.responseSize == nil || i.errors == nil {
+			return
+		}
+
+		set := metric.WithAttributes(append(attrs, StatusCodeKey.Int(result.StatusCode))...)
+		i.duration.Record(ctx, result.Duration.Seconds(), set)
+		i.requestSize.Record(ctx, int64(info.RequestSize), set)
+		i.responseSize.Record(ctx, int64(result.ResponseSize), set)
+		if result.Err != nil {
+			i.errors.Add(ctx, 1, set)
+		}
+	}
+}
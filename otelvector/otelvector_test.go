@@ -0,0 +1,78 @@
+package otelvector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/query/ns" {
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","score":1}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401}`))
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	index := vector.NewIndexWith(vector.Options{
+		Url:   server.URL,
+		Token: "token",
+		Instrumentation: New(Options{
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+			MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		}),
+	})
+
+	_, err := index.Namespace("ns").Query(vector.Query{Vector: []float32{1, 0}, TopK: 5})
+	require.NoError(t, err)
+
+	_, err = index.Info()
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Equal(t, 2, len(ended))
+
+	require.Equal(t, "vector.query", ended[0].Name())
+	attrs := map[string]any{}
+	for _, attr := range ended[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	require.Equal(t, "ns", attrs[string(NamespaceKey)])
+	require.Equal(t, int64(5), attrs[string(TopKKey)])
+	require.Equal(t, int64(http.StatusOK), attrs[string(StatusCodeKey)])
+	require.Equal(t, codes.Unset, ended[0].Status().Code)
+
+	require.Equal(t, "vector.info", ended[1].Name())
+	require.Equal(t, codes.Error, ended[1].Status().Code)
+	require.Equal(t, "Unauthorized", ended[1].Status().Description)
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &metrics))
+	require.Equal(t, 1, len(metrics.ScopeMetrics))
+
+	names := map[string]bool{}
+	for _, m := range metrics.ScopeMetrics[0].Metrics {
+		names[m.Name] = true
+	}
+	require.Equal(t, map[string]bool{
+		"upstash.vector.request.duration": true,
+		"upstash.vector.request.size":     true,
+		"upstash.vector.response.size":    true,
+		"upstash.vector.request.errors":   true,
+	}, names)
+}
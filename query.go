@@ -13,10 +13,41 @@ func (ix *Index) Query(q Query) (scores []VectorScore, err error) {
 }
 
 func (ix *Index) queryInternal(q Query, ns string) (scores []VectorScore, err error) {
-	data, err := ix.sendJson(buildPath(queryPath, ns), q)
+	if err = ix.validateQuery(q.Vector, q.SparseVector); err != nil {
+		return
+	}
+	scores, err = ix.sendQuery(buildPath(queryPath, ns), ns, q)
 	if err != nil {
 		return
 	}
-	scores, err = parseResponse[[]VectorScore](data)
+	scores, _ = filterScores(scores, q.MinScore, q.MaxResults)
+	return
+}
+
+// filterScores drops the scores lower than minScore, if it is not nil, from the sorted
+// scores, and returns at most maxResults of the remaining ones when maxResults is positive.
+// It also reports whether any score is dropped for being lower than minScore.
+func filterScores(scores []VectorScore, minScore *float32, maxResults int) (filtered []VectorScore, dropped bool) {
+	filtered = scores
+	if minScore != nil {
+		for i, score := range scores {
+			if score.Score < *minScore {
+				filtered, dropped = scores[:i], true
+				break
+			}
+		}
+	}
+	if maxResults > 0 && len(filtered) > maxResults {
+		filtered = filtered[:maxResults]
+	}
 	return
 }
+
+// resultLimit returns the maximum number of vectors a query with the
+// given topK and maxResults might return, or 0 if there is no limit.
+func resultLimit(topK int, maxResults int) int {
+	if maxResults > 0 && (topK <= 0 || maxResults < topK) {
+		return maxResults
+	}
+	return topK
+}
@@ -0,0 +1,123 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const defaultQueryByIdTopK = 10
+
+// ErrVectorNotFound is returned when a vector needed by an
+// operation does not exist in the index.
+var ErrVectorNotFound = errors.New("vector not found")
+
+// QueryById returns the vectors in the default namespace that are similar to the
+// vector with the given id. The vector is fetched from the index and its dense and
+// sparse values are used as the query vector. The vector itself, and optionally the
+// vectors whose ids start with q.ExcludePrefix, are excluded from the result.
+// If the vector does not exist, an error wrapping ErrVectorNotFound is returned.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ix *Index) QueryById(q QueryById) (scores []VectorScore, err error) {
+	return ix.queryByIdInternal(q, defaultNamespace)
+}
+
+// QueryById returns the vectors in the namespace that are similar to the
+// vector with the given id. See Index.QueryById for details.
+func (ns *Namespace) QueryById(q QueryById) (scores []VectorScore, err error) {
+	return ns.index.queryByIdInternal(q, ns.ns)
+}
+
+func (ix *Index) queryByIdInternal(q QueryById, ns string) (scores []VectorScore, err error) {
+	vectors, err := ix.fetchInternal(Fetch{
+		Ids:            []string{q.Id},
+		IncludeVectors: true,
+	}, ns)
+	if err != nil {
+		return
+	}
+	if len(vectors) == 0 || vectors[0].Id == "" {
+		err = fmt.Errorf("%w: %s", ErrVectorNotFound, q.Id)
+		return
+	}
+
+	source := vectors[0]
+	topK := q.TopK
+	if topK <= 0 {
+		topK = defaultQueryByIdTopK
+	}
+
+	excluded := func(id string) bool {
+		return id == q.Id || (q.ExcludePrefix != "" && strings.HasPrefix(id, q.ExcludePrefix))
+	}
+
+	if q.ExcludePrefix == "" {
+		// only the source vector is excluded, so one more
+		// vector than needed is enough.
+		var res []VectorScore
+		res, err = ix.queryInternal(Query{
+			Vector:            source.Vector,
+			SparseVector:      source.SparseVector,
+			TopK:              topK + 1,
+			IncludeVectors:    q.IncludeVectors,
+			IncludeMetadata:   q.IncludeMetadata,
+			IncludeData:       q.IncludeData,
+			Filter:            q.Filter,
+			WeightingStrategy: q.WeightingStrategy,
+			FusionAlgorithm:   q.FusionAlgorithm,
+		}, ns)
+		if err != nil {
+			return
+		}
+		scores = excludeScores(res, excluded, topK)
+		return
+	}
+
+	// the number of vectors sharing the prefix is not known,
+	// so the next pages are fetched until enough vectors are found.
+	page, handle, err := ix.resumableQueryInternal(ResumableQuery{
+		Vector:            source.Vector,
+		SparseVector:      source.SparseVector,
+		TopK:              topK + 1,
+		IncludeVectors:    q.IncludeVectors,
+		IncludeMetadata:   q.IncludeMetadata,
+		IncludeData:       q.IncludeData,
+		Filter:            q.Filter,
+		WeightingStrategy: q.WeightingStrategy,
+		FusionAlgorithm:   q.FusionAlgorithm,
+	}, ns)
+	if err != nil {
+		return
+	}
+	defer handle.Close()
+
+	requested := topK + 1
+	for {
+		scores = append(scores, excludeScores(page, excluded, topK-len(scores))...)
+		if len(scores) >= topK || len(page) < requested {
+			return
+		}
+
+		requested = topK
+		if page, err = handle.Next(ResumableQueryNext{AdditionalK: requested}); err != nil {
+			return
+		}
+	}
+}
+
+// excludeScores returns at most limit scores that are not excluded.
+func excludeScores(scores []VectorScore, excluded func(id string) bool, limit int) []VectorScore {
+	result := make([]VectorScore, 0, min(limit, len(scores)))
+	for _, score := range scores {
+		if len(result) >= limit {
+			break
+		}
+		if !excluded(score.Id) {
+			result = append(result, score)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package vector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryById(t *testing.T) {
+	for _, ns := range namespaces {
+		for _, tcType := range testClientTypes {
+			t.Run("namespace_"+ns+"_index_type_"+string(tcType), func(t *testing.T) {
+				client, err := newTestClient(tcType, ns)
+				require.NoError(t, err)
+
+				upsert := func(id string, v []float32, sv *SparseVector) Upsert {
+					switch tcType {
+					case testClientTypeDense:
+						return Upsert{Id: id, Vector: v, Metadata: map[string]any{"id": id}}
+					case testClientTypeSparse:
+						return Upsert{Id: id, SparseVector: sv, Metadata: map[string]any{"id": id}}
+					default:
+						return Upsert{Id: id, Vector: v, SparseVector: sv, Metadata: map[string]any{"id": id}}
+					}
+				}
+
+				err = client.UpsertMany([]Upsert{
+					upsert("doc1-0", []float32{1, 0}, &SparseVector{Indices: []int32{0}, Values: []float32{1}}),
+					upsert("doc1-1", []float32{1, 0.1}, &SparseVector{Indices: []int32{0, 1}, Values: []float32{0.9, 0.1}}),
+					upsert("doc2-0", []float32{1, 0.3}, &SparseVector{Indices: []int32{0, 1}, Values: []float32{0.5, 0.5}}),
+					upsert("doc3-0", []float32{0.1, 1}, &SparseVector{Indices: []int32{0, 2}, Values: []float32{0.1, 0.9}}),
+				})
+				require.NoError(t, err)
+
+				require.Eventually(t, func() bool {
+					info, err := client.Info()
+					require.NoError(t, err)
+					return info.PendingVectorCount == 0
+				}, 10*time.Second, 1*time.Second)
+
+				t.Run("excludes itself", func(t *testing.T) {
+					scores, err := client.QueryById(QueryById{
+						Id:              "doc1-0",
+						TopK:            1,
+						IncludeMetadata: true,
+					})
+					require.NoError(t, err)
+					require.Equal(t, 1, len(scores))
+					require.Equal(t, "doc1-1", scores[0].Id)
+					require.Equal(t, map[string]any{"id": "doc1-1"}, scores[0].Metadata)
+				})
+
+				t.Run("excludes prefix", func(t *testing.T) {
+					scores, err := client.QueryById(QueryById{
+						Id:            "doc1-0",
+						ExcludePrefix: "doc1-",
+						TopK:          2,
+					})
+					require.NoError(t, err)
+					require.Equal(t, 2, len(scores))
+					require.Equal(t, "doc2-0", scores[0].Id)
+					require.Equal(t, "doc3-0", scores[1].Id)
+				})
+
+				t.Run("with metadata filtering", func(t *testing.T) {
+					scores, err := client.QueryById(QueryById{
+						Id:     "doc1-0",
+						TopK:   5,
+						Filter: `id = 'doc3-0'`,
+					})
+					require.NoError(t, err)
+					require.Equal(t, 1, len(scores))
+					require.Equal(t, "doc3-0", scores[0].Id)
+				})
+
+				t.Run("non existing id", func(t *testing.T) {
+					_, err := client.QueryById(QueryById{
+						Id: randomString(),
+					})
+					require.True(t, errors.Is(err, ErrVectorNotFound))
+				})
+			})
+		}
+	}
+}
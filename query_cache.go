@@ -0,0 +1,205 @@
+package vector
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQueryCacheTTL        = 10 * time.Second
+	defaultQueryCacheMaxEntries = 1000
+	defaultQueryCacheWriteDelay = time.Second
+)
+
+// QueryCacheOptions configures the caching of the query results.
+//
+// Results of Query and QueryData are cached by their namespace and
+// payload. The cached results of a namespace are invalidated whenever
+// a write to that namespace (upsert, update, delete, reset, or namespace
+// deletion) is issued through the same client. Writes issued through other
+// clients are not visible to the cache until the cached results expire.
+//
+// Upserted vectors are indexed asynchronously by the server, so the queries
+// right after a write might not see it yet. To avoid caching such results for
+// a full TTL, the results of a namespace are not cached for WriteDelay after
+// a write to it.
+//
+// The cached results are copied when they are returned,
+// so they can be modified by the callers.
+type QueryCacheOptions struct {
+	// How long the cached results are served.
+	// If not provided, defaults to 10 seconds.
+	TTL time.Duration
+
+	// The maximum number of cached query results. When the limit is
+	// reached, the least recently used results are evicted.
+	// If not provided, defaults to 1000.
+	MaxEntries int
+
+	// How long the results of a namespace are not cached after a write
+	// to it, while the written vectors might still be pending to be indexed.
+	// If not provided, defaults to 1 second.
+	WriteDelay time.Duration
+}
+
+type queryCacheEntry struct {
+	key        string
+	ns         string
+	generation uint64
+	expiresAt  time.Time
+	scores     []VectorScore
+}
+
+// queryCache is a LRU cache of query results.
+//
+// Each namespace has a generation that is incremented on writes,
+// and the entries of older generations are treated as misses.
+type queryCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	maxEntries  int
+	writeDelay  time.Duration
+	entries     map[string]*list.Element
+	lru         *list.List
+	generations map[string]uint64
+	writtenAt   map[string]time.Time
+}
+
+func newQueryCache(options QueryCacheOptions) *queryCache {
+	if options.TTL <= 0 {
+		options.TTL = defaultQueryCacheTTL
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaultQueryCacheMaxEntries
+	}
+	if options.WriteDelay <= 0 {
+		options.WriteDelay = defaultQueryCacheWriteDelay
+	}
+	return &queryCache{
+		ttl:         options.TTL,
+		maxEntries:  options.MaxEntries,
+		writeDelay:  options.WriteDelay,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		generations: map[string]uint64{},
+		writtenAt:   map[string]time.Time{},
+	}
+}
+
+func queryCacheKey(path string, body []byte) string {
+	return path + "\x00" + string(body)
+}
+
+// get returns a copy of the cached result for the key, if it is
+// still valid. It also returns the current generation of the namespace
+// that should be used while putting the result of a cache miss.
+func (c *queryCache) get(ns string, key string) (scores []VectorScore, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation = c.generations[ns]
+	elem, found := c.entries[key]
+	if !found {
+		return
+	}
+
+	entry := elem.Value.(*queryCacheEntry)
+	if entry.generation != generation || time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return
+	}
+
+	c.lru.MoveToFront(elem)
+	return cloneVectorScores(entry.scores), generation, true
+}
+
+// put caches the result, unless the namespace is written to
+// after the generation is read, or too recently.
+func (c *queryCache) put(ns string, key string, generation uint64, scores []VectorScore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[ns] != generation {
+		return
+	}
+	if writtenAt, ok := c.writtenAt[ns]; ok {
+		if time.Since(writtenAt) < c.writeDelay {
+			return
+		}
+		delete(c.writtenAt, ns)
+	}
+
+	if elem, found := c.entries[key]; found {
+		c.remove(elem)
+	}
+
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{
+		key:        key,
+		ns:         ns,
+		generation: generation,
+		expiresAt:  time.Now().Add(c.ttl),
+		scores:     cloneVectorScores(scores),
+	})
+
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate invalidates all the cached results of the namespace.
+func (c *queryCache) invalidate(ns string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[ns]++
+	c.writtenAt[ns] = time.Now()
+}
+
+func (c *queryCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*queryCacheEntry)
+	delete(c.entries, entry.key)
+}
+
+// sendQuery sends the query and parses the scores, serving
+// the results from the query cache when it is enabled.
+func (ix *Index) sendQuery(path string, ns string, q any) (scores []VectorScore, err error) {
+	if ix.queryCache == nil {
+		var data []byte
+		if data, err = ix.sendJson(path, q); err != nil {
+			return
+		}
+		return parseVectorScores(data)
+	}
+
+	body, err := json.Marshal(q)
+	if err != nil {
+		return
+	}
+
+	key := queryCacheKey(path, body)
+	scores, generation, ok := ix.queryCache.get(ns, key)
+	if ok {
+		return
+	}
+
+	data, err := ix.sendRequest(newRequest(path, body, q))
+	if err != nil {
+		return
+	}
+	if scores, err = parseVectorScores(data); err != nil {
+		return
+	}
+
+	ix.queryCache.put(ns, key, generation, scores)
+	return
+}
+
+// invalidateQueryCache invalidates the cached query results
+// of the namespace, if the query cache is enabled.
+func (ix *Index) invalidateQueryCache(ns string) {
+	if ix.queryCache != nil {
+		ix.queryCache.invalidate(ns)
+	}
+}
@@ -0,0 +1,184 @@
+package vector
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	scores := []VectorScore{{Id: "a", Score: 1}}
+
+	t.Run("get and put", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{})
+
+		_, generation, ok := c.get("ns", "key")
+		require.False(t, ok)
+
+		c.put("ns", "key", generation, scores)
+		cached, _, ok := c.get("ns", "key")
+		require.True(t, ok)
+		require.Equal(t, scores, cached)
+
+		cached[0].Id = "b"
+		cached, _, ok = c.get("ns", "key")
+		require.True(t, ok)
+		require.Equal(t, "a", cached[0].Id)
+	})
+
+	t.Run("deep copy", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{})
+		c.put("ns", "key", 0, []VectorScore{{
+			Id:           "a",
+			Vector:       []float32{1, 2},
+			SparseVector: &SparseVector{Indices: []int32{1}, Values: []float32{0.5}},
+			Metadata:     map[string]any{"tags": []any{"x"}, "nested": map[string]any{"n": 1.0}},
+		}})
+
+		cached, _, _ := c.get("ns", "key")
+		cached[0].Vector[0] = 10
+		cached[0].SparseVector.Values[0] = 10
+		cached[0].Metadata["tags"].([]any)[0] = "y"
+		cached[0].Metadata["nested"].(map[string]any)["n"] = 2.0
+		cached[0].Metadata["new"] = true
+
+		cached, _, _ = c.get("ns", "key")
+		require.Equal(t, VectorScore{
+			Id:           "a",
+			Vector:       []float32{1, 2},
+			SparseVector: &SparseVector{Indices: []int32{1}, Values: []float32{0.5}},
+			Metadata:     map[string]any{"tags": []any{"x"}, "nested": map[string]any{"n": 1.0}},
+		}, cached[0])
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{TTL: 10 * time.Millisecond})
+		c.put("ns", "key", 0, scores)
+		time.Sleep(20 * time.Millisecond)
+
+		_, _, ok := c.get("ns", "key")
+		require.False(t, ok)
+		require.Equal(t, 0, c.lru.Len())
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{MaxEntries: 2})
+		c.put("ns", "key0", 0, scores)
+		c.put("ns", "key1", 0, scores)
+		_, _, ok := c.get("ns", "key0")
+		require.True(t, ok)
+
+		c.put("ns", "key2", 0, scores)
+		_, _, ok = c.get("ns", "key1")
+		require.False(t, ok)
+		_, _, ok = c.get("ns", "key0")
+		require.True(t, ok)
+		_, _, ok = c.get("ns", "key2")
+		require.True(t, ok)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{})
+		c.put("ns", "key0", 0, scores)
+		c.put("other", "key1", 0, scores)
+
+		c.invalidate("ns")
+		_, _, ok := c.get("ns", "key0")
+		require.False(t, ok)
+		_, _, ok = c.get("other", "key1")
+		require.True(t, ok)
+	})
+
+	t.Run("write delay", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{WriteDelay: 20 * time.Millisecond})
+		c.invalidate("ns")
+
+		_, generation, _ := c.get("ns", "key")
+		c.put("ns", "key", generation, scores)
+		_, _, ok := c.get("ns", "key")
+		require.False(t, ok)
+
+		time.Sleep(30 * time.Millisecond)
+		c.put("ns", "key", generation, scores)
+		_, _, ok = c.get("ns", "key")
+		require.True(t, ok)
+	})
+
+	t.Run("write during query", func(t *testing.T) {
+		c := newQueryCache(QueryCacheOptions{})
+		_, generation, _ := c.get("ns", "key")
+		c.invalidate("ns")
+		c.put("ns", "key", generation, scores)
+
+		_, _, ok := c.get("ns", "key")
+		require.False(t, ok)
+	})
+}
+
+func TestQueryWithCache(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			client, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			cached := NewIndexWith(Options{
+				Url:        os.Getenv(UrlEnvProperty),
+				Token:      os.Getenv(TokenEnvProperty),
+				QueryCache: &QueryCacheOptions{TTL: time.Minute},
+			}).Namespace(ns)
+
+			id0 := randomString()
+			err = cached.Upsert(Upsert{
+				Id:     id0,
+				Vector: []float32{0.6, 0.8},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			query := Query{
+				Vector: []float32{0.6, 0.8},
+				TopK:   5,
+			}
+
+			scores, err := cached.Query(query)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(scores))
+
+			// writes from other clients are not visible to the cache
+			err = client.Upsert(Upsert{
+				Id:     randomString(),
+				Vector: []float32{0.8, 0.6},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			scores, err = cached.Query(query)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(scores))
+
+			// writes from the same client invalidate the cache
+			ok, err := cached.Update(Update{
+				Id:       id0,
+				Metadata: map[string]any{"foo": "bar"},
+			})
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			scores, err = cached.Query(query)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(scores))
+		})
+	}
+}
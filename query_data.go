@@ -13,10 +13,10 @@ func (ix *Index) QueryData(q QueryData) (scores []VectorScore, err error) {
 }
 
 func (ix *Index) queryDataInternal(q QueryData, ns string) (scores []VectorScore, err error) {
-	data, err := ix.sendJson(buildPath(queryDataPath, ns), q)
+	scores, err = ix.sendQuery(buildPath(queryDataPath, ns), ns, q)
 	if err != nil {
 		return
 	}
-	scores, err = parseResponse[[]VectorScore](data)
+	scores, _ = filterScores(scores, q.MinScore, q.MaxResults)
 	return
 }
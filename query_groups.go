@@ -0,0 +1,181 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	defaultQueryGroups                 = 10
+	defaultQueryGroupSize              = 1
+	defaultQueryGroupsCandidatesFactor = 10
+)
+
+// QueryGroups returns the result of the query for the given vector in the default namespace,
+// grouped by the value of the q.GroupBy metadata key.
+// The result pages are fetched with a resumable query until q.Groups groups with q.GroupSize
+// vectors each are filled, the results are exhausted, or q.MaxCandidates vectors are scanned.
+// The returned groups are sorted in descending order of their best scores.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// Metadata of the vectors are always returned.
+func (ix *Index) QueryGroups(q QueryGroups) (groups []ScoreGroup, err error) {
+	return ix.queryGroupsInternal(q, defaultNamespace)
+}
+
+// QueryDataGroups returns the result of the query for the given data in the default namespace,
+// grouped by the value of the q.GroupBy metadata key. The data is converted to an embedding
+// on the server. See Index.QueryGroups for details.
+func (ix *Index) QueryDataGroups(q QueryDataGroups) (groups []ScoreGroup, err error) {
+	return ix.queryDataGroupsInternal(q, defaultNamespace)
+}
+
+// QueryGroups returns the result of the query for the given vector in the namespace,
+// grouped by the value of the q.GroupBy metadata key. See Index.QueryGroups for details.
+func (ns *Namespace) QueryGroups(q QueryGroups) (groups []ScoreGroup, err error) {
+	return ns.index.queryGroupsInternal(q, ns.ns)
+}
+
+// QueryDataGroups returns the result of the query for the given data in the namespace,
+// grouped by the value of the q.GroupBy metadata key. See Index.QueryGroups for details.
+func (ns *Namespace) QueryDataGroups(q QueryDataGroups) (groups []ScoreGroup, err error) {
+	return ns.index.queryDataGroupsInternal(q, ns.ns)
+}
+
+func (ix *Index) queryGroupsInternal(q QueryGroups, ns string) (groups []ScoreGroup, err error) {
+	g := newGrouper(q.GroupBy, q.Groups, q.GroupSize, q.PageSize, q.MaxCandidates)
+	return g.run(func(topK int) ([]VectorScore, *ResumableQueryHandle, error) {
+		return ix.resumableQueryInternal(ResumableQuery{
+			Vector:            q.Vector,
+			SparseVector:      q.SparseVector,
+			TopK:              topK,
+			IncludeVectors:    q.IncludeVectors,
+			IncludeMetadata:   true,
+			IncludeData:       q.IncludeData,
+			Filter:            q.Filter,
+			WeightingStrategy: q.WeightingStrategy,
+			FusionAlgorithm:   q.FusionAlgorithm,
+		}, ns)
+	})
+}
+
+func (ix *Index) queryDataGroupsInternal(q QueryDataGroups, ns string) (groups []ScoreGroup, err error) {
+	g := newGrouper(q.GroupBy, q.Groups, q.GroupSize, q.PageSize, q.MaxCandidates)
+	return g.run(func(topK int) ([]VectorScore, *ResumableQueryHandle, error) {
+		return ix.resumableQueryDataInternal(ResumableQueryData{
+			Data:              q.Data,
+			TopK:              topK,
+			IncludeVectors:    q.IncludeVectors,
+			IncludeMetadata:   true,
+			IncludeData:       q.IncludeData,
+			Filter:            q.Filter,
+			WeightingStrategy: q.WeightingStrategy,
+			FusionAlgorithm:   q.FusionAlgorithm,
+			QueryMode:         q.QueryMode,
+		}, ns)
+	})
+}
+
+type grouper struct {
+	groupBy       string
+	maxGroups     int
+	groupSize     int
+	pageSize      int
+	maxCandidates int
+
+	groups  []ScoreGroup
+	keys    map[string]int
+	full    int
+	scanned int
+}
+
+func newGrouper(groupBy string, groups int, groupSize int, pageSize int, maxCandidates int) *grouper {
+	if groups <= 0 {
+		groups = defaultQueryGroups
+	}
+	if groupSize <= 0 {
+		groupSize = defaultQueryGroupSize
+	}
+	if pageSize <= 0 {
+		pageSize = groups * groupSize
+	}
+	if maxCandidates <= 0 {
+		maxCandidates = defaultQueryGroupsCandidatesFactor * groups * groupSize
+	}
+	return &grouper{
+		groupBy:       groupBy,
+		maxGroups:     groups,
+		groupSize:     groupSize,
+		pageSize:      pageSize,
+		maxCandidates: maxCandidates,
+		keys:          map[string]int{},
+	}
+}
+
+func (g *grouper) run(start func(topK int) ([]VectorScore, *ResumableQueryHandle, error)) ([]ScoreGroup, error) {
+	if g.groupBy == "" {
+		return nil, errors.New("metadata key to group by is required")
+	}
+
+	requested := min(g.pageSize, g.maxCandidates)
+	page, handle, err := start(requested)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	for {
+		if err = g.add(page); err != nil {
+			return nil, err
+		}
+		if g.filled() || len(page) < requested || g.scanned >= g.maxCandidates {
+			return g.groups, nil
+		}
+
+		requested = min(g.pageSize, g.maxCandidates-g.scanned)
+		if page, err = handle.Next(ResumableQueryNext{AdditionalK: requested}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// add adds the scores to their groups. Since the scores are sorted, the
+// groups are created in descending order of their best scores.
+func (g *grouper) add(scores []VectorScore) error {
+	for _, score := range scores {
+		g.scanned++
+
+		value, ok := score.Metadata[g.groupBy]
+		if !ok {
+			continue
+		}
+
+		key, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		i, ok := g.keys[string(key)]
+		if !ok {
+			if len(g.groups) == g.maxGroups {
+				continue
+			}
+			i = len(g.groups)
+			g.keys[string(key)] = i
+			g.groups = append(g.groups, ScoreGroup{Value: value})
+		}
+
+		group := &g.groups[i]
+		if len(group.Scores) == g.groupSize {
+			continue
+		}
+		group.Scores = append(group.Scores, score)
+		if len(group.Scores) == g.groupSize {
+			g.full++
+		}
+	}
+	return nil
+}
+
+func (g *grouper) filled() bool {
+	return len(g.groups) == g.maxGroups && g.full == g.maxGroups
+}
@@ -0,0 +1,88 @@
+package vector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrouper(t *testing.T) {
+	score := func(id string, doc any) VectorScore {
+		if doc == nil {
+			return VectorScore{Id: id}
+		}
+		return VectorScore{Id: id, Metadata: map[string]any{"doc": doc}}
+	}
+
+	g := newGrouper("doc", 2, 2, 0, 0)
+	require.Equal(t, 4, g.pageSize)
+	require.Equal(t, 40, g.maxCandidates)
+
+	err := g.add([]VectorScore{
+		score("a0", "a"),
+		score("n0", nil),
+		score("b0", float64(1)),
+		score("a1", "a"),
+		score("c0", "c"),
+		score("a2", "a"),
+	})
+	require.NoError(t, err)
+	require.False(t, g.filled())
+	require.Equal(t, 6, g.scanned)
+
+	require.NoError(t, g.add([]VectorScore{score("b1", float64(1))}))
+	require.True(t, g.filled())
+	require.Equal(t, []ScoreGroup{
+		{Value: "a", Scores: []VectorScore{score("a0", "a"), score("a1", "a")}},
+		{Value: float64(1), Scores: []VectorScore{score("b0", float64(1)), score("b1", float64(1))}},
+	}, g.groups)
+
+	_, err = newGrouper("", 0, 0, 0, 0).run(nil)
+	require.Error(t, err)
+}
+
+func TestQueryGroups(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			client, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			err = client.UpsertMany([]Upsert{
+				{Id: "a0", Vector: []float32{1, 0}, Metadata: map[string]any{"doc": "a"}},
+				{Id: "a1", Vector: []float32{0.99, 0.01}, Metadata: map[string]any{"doc": "a"}},
+				{Id: "a2", Vector: []float32{0.98, 0.02}, Metadata: map[string]any{"doc": "a"}},
+				{Id: "b0", Vector: []float32{0.9, 0.1}, Metadata: map[string]any{"doc": "b"}},
+				{Id: "c0", Vector: []float32{0.5, 0.5}, Metadata: map[string]any{"doc": "c"}},
+				{Id: "d0", Vector: []float32{0.95, 0.05}},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			groups, err := client.QueryGroups(QueryGroups{
+				Vector:    []float32{1, 0},
+				GroupBy:   "doc",
+				Groups:    2,
+				GroupSize: 2,
+				PageSize:  2,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(groups))
+
+			require.Equal(t, "a", groups[0].Value)
+			require.Equal(t, 2, len(groups[0].Scores))
+			require.Equal(t, "a0", groups[0].Scores[0].Id)
+			require.Equal(t, "a1", groups[0].Scores[1].Id)
+
+			require.Equal(t, "b", groups[1].Value)
+			require.Equal(t, 1, len(groups[1].Scores))
+			require.Equal(t, "b0", groups[1].Scores[0].Id)
+			require.Equal(t, map[string]any{"doc": "b"}, groups[1].Scores[0].Metadata)
+		})
+	}
+}
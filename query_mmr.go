@@ -0,0 +1,161 @@
+package vector
+
+import (
+	"math"
+
+	"github.com/upstash/vector-go/internal/score"
+)
+
+const (
+	defaultMMRTopK      = 10
+	defaultMMRLambda    = 0.5
+	mmrFetchKMultiplier = 4
+	mmrNegativeInfinity = float32(-math.MaxFloat32)
+)
+
+// QueryMMR returns the result of the query for the given vector in the default namespace,
+// re-ranked with maximal marginal relevance to return diverse vectors.
+// q.FetchK candidate vectors are queried from the index, and q.TopK of them are selected
+// one by one, maximizing lambda * similarity to the query vector minus
+// (1 - lambda) * the maximum similarity to the already selected vectors.
+// The similarities are calculated with the similarity function of the index for the dense
+// vectors, and with the inner product for the sparse vectors. For hybrid indexes, only the
+// dense vectors are used.
+// The returned list will contain vectors in the order they are selected, with their
+// scores to the query vector as returned from the index.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ix *Index) QueryMMR(q QueryMMR) (scores []VectorScore, err error) {
+	return ix.queryMMRInternal(q, defaultNamespace)
+}
+
+// QueryMMR returns the result of the query for the given vector in the namespace,
+// re-ranked with maximal marginal relevance to return diverse vectors.
+// See Index.QueryMMR for details.
+func (ns *Namespace) QueryMMR(q QueryMMR) (scores []VectorScore, err error) {
+	return ns.index.queryMMRInternal(q, ns.ns)
+}
+
+func (ix *Index) queryMMRInternal(q QueryMMR, ns string) (scores []VectorScore, err error) {
+	topK := q.TopK
+	if topK <= 0 {
+		topK = defaultMMRTopK
+	}
+	fetchK := q.FetchK
+	if fetchK <= 0 {
+		fetchK = topK * mmrFetchKMultiplier
+	} else if fetchK < topK {
+		fetchK = topK
+	}
+	lambda := float32(defaultMMRLambda)
+	if q.Lambda != nil {
+		lambda = *q.Lambda
+	}
+
+	candidates, err := ix.queryInternal(Query{
+		Vector:            q.Vector,
+		SparseVector:      q.SparseVector,
+		TopK:              fetchK,
+		IncludeVectors:    true,
+		IncludeMetadata:   q.IncludeMetadata,
+		IncludeData:       q.IncludeData,
+		Filter:            q.Filter,
+		WeightingStrategy: q.WeightingStrategy,
+		FusionAlgorithm:   q.FusionAlgorithm,
+	}, ns)
+	if err != nil {
+		return
+	}
+
+	var similarity func(a []float32, sa *SparseVector, b []float32, sb *SparseVector) (float32, error)
+	if q.Vector != nil {
+		info, err := ix.cachedInfo()
+		if err != nil {
+			return nil, err
+		}
+		similarity = func(a []float32, _ *SparseVector, b []float32, _ *SparseVector) (float32, error) {
+			return score.Dense(info.SimilarityFunction, a, b)
+		}
+	} else {
+		similarity = func(_ []float32, a *SparseVector, _ []float32, b *SparseVector) (float32, error) {
+			if a == nil || b == nil {
+				return 0, nil
+			}
+			return score.Sparse(a.Indices, a.Values, b.Indices, b.Values)
+		}
+	}
+
+	order, err := maximalMarginalRelevance(q.Vector, q.SparseVector, candidates, topK, lambda, similarity)
+	if err != nil {
+		return
+	}
+
+	scores = make([]VectorScore, len(order))
+	for i, c := range order {
+		scores[i] = candidates[c]
+		if !q.IncludeVectors {
+			scores[i].Vector = nil
+			scores[i].SparseVector = nil
+		}
+	}
+	return
+}
+
+// maximalMarginalRelevance returns the positions of at most k candidates
+// in the order they are selected with the maximal marginal relevance.
+func maximalMarginalRelevance(
+	vector []float32,
+	sparseVector *SparseVector,
+	candidates []VectorScore,
+	k int,
+	lambda float32,
+	similarity func(a []float32, sa *SparseVector, b []float32, sb *SparseVector) (float32, error),
+) ([]int, error) {
+	relevance := make([]float32, len(candidates))
+	for i, c := range candidates {
+		s, err := similarity(vector, sparseVector, c.Vector, c.SparseVector)
+		if err != nil {
+			return nil, err
+		}
+		relevance[i] = s
+	}
+
+	// maximum similarity of each candidate to the selected ones
+	redundancy := make([]float32, len(candidates))
+	for i := range redundancy {
+		redundancy[i] = mmrNegativeInfinity
+	}
+	selected := make([]bool, len(candidates))
+
+	order := make([]int, 0, min(k, len(candidates)))
+	for len(order) < k && len(order) < len(candidates) {
+		best, bestScore := -1, mmrNegativeInfinity
+		for i := range candidates {
+			if selected[i] {
+				continue
+			}
+			score := lambda * relevance[i]
+			if len(order) > 0 {
+				score -= (1 - lambda) * redundancy[i]
+			}
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		selected[best] = true
+		order = append(order, best)
+
+		for i, c := range candidates {
+			if selected[i] {
+				continue
+			}
+			s, err := similarity(c.Vector, c.SparseVector, candidates[best].Vector, candidates[best].SparseVector)
+			if err != nil {
+				return nil, err
+			}
+			redundancy[i] = max(redundancy[i], s)
+		}
+	}
+	return order, nil
+}
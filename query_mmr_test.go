@@ -0,0 +1,131 @@
+package vector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go/internal/score"
+)
+
+func TestSimilarityFunctions(t *testing.T) {
+	// The scores of the similarity functions are calculated with the names of the score package.
+	require.Equal(t, score.Cosine, SimilarityFunctionCosine)
+	require.Equal(t, score.Euclidean, SimilarityFunctionEuclidean)
+	require.Equal(t, score.DotProduct, SimilarityFunctionDotProduct)
+}
+
+func TestMaximalMarginalRelevance(t *testing.T) {
+	candidates := []VectorScore{
+		{Id: "id0", Vector: []float32{1, 0}},
+		{Id: "id1", Vector: []float32{1, 0.01}},
+		{Id: "id2", Vector: []float32{0.7, 0.7}},
+	}
+	cosine := func(a []float32, _ *SparseVector, b []float32, _ *SparseVector) (float32, error) {
+		return score.Dense(SimilarityFunctionCosine, a, b)
+	}
+
+	t.Run("relevance", func(t *testing.T) {
+		order, err := maximalMarginalRelevance([]float32{1, 0}, nil, candidates, 3, 1, cosine)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2}, order)
+	})
+
+	t.Run("diversity", func(t *testing.T) {
+		order, err := maximalMarginalRelevance([]float32{1, 0}, nil, candidates, 2, 0.3, cosine)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 2}, order)
+	})
+
+	t.Run("fewer candidates", func(t *testing.T) {
+		order, err := maximalMarginalRelevance([]float32{1, 0}, nil, candidates[:1], 5, 0.5, cosine)
+		require.NoError(t, err)
+		require.Equal(t, []int{0}, order)
+	})
+}
+
+func TestQueryMMR(t *testing.T) {
+	for _, ns := range namespaces {
+		for _, tcType := range testClientTypes {
+			t.Run("namespace_"+ns+"_index_type_"+string(tcType), func(t *testing.T) {
+				client, err := newTestClient(tcType, ns)
+				require.NoError(t, err)
+
+				upsert := func(id string, v []float32, sv *SparseVector) Upsert {
+					switch tcType {
+					case testClientTypeDense:
+						return Upsert{Id: id, Vector: v}
+					case testClientTypeSparse:
+						return Upsert{Id: id, SparseVector: sv}
+					default:
+						return Upsert{Id: id, Vector: v, SparseVector: sv}
+					}
+				}
+
+				err = client.UpsertMany([]Upsert{
+					upsert("id0", []float32{1, 0}, &SparseVector{Indices: []int32{0}, Values: []float32{1}}),
+					upsert("id1", []float32{1, 0.01}, &SparseVector{Indices: []int32{0}, Values: []float32{0.99}}),
+					upsert("id2", []float32{0.7, 0.7}, &SparseVector{Indices: []int32{0, 1}, Values: []float32{0.5, 0.5}}),
+				})
+				require.NoError(t, err)
+
+				require.Eventually(t, func() bool {
+					info, err := client.Info()
+					require.NoError(t, err)
+					return info.PendingVectorCount == 0
+				}, 10*time.Second, 1*time.Second)
+
+				q := upsert("", []float32{1, 0}, &SparseVector{Indices: []int32{0}, Values: []float32{1}})
+				lambda := float32(0.3)
+				query := QueryMMR{
+					Vector:       q.Vector,
+					SparseVector: q.SparseVector,
+					TopK:         2,
+					FetchK:       3,
+					Lambda:       &lambda,
+				}
+
+				scores, err := client.QueryMMR(query)
+				require.NoError(t, err)
+				require.Equal(t, 2, len(scores))
+				require.Equal(t, "id0", scores[0].Id)
+				require.Equal(t, "id2", scores[1].Id)
+				require.Nil(t, scores[0].Vector)
+				require.Nil(t, scores[0].SparseVector)
+			})
+		}
+	}
+}
+
+func TestQueryMMRLambda(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == infoPath {
+			_, _ = w.Write([]byte(`{"result":{"dimension":2,"similarityFunction":"COSINE"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[
+			{"id":"id0","score":1,"vector":[1,0]},
+			{"id":"id1","score":0.99,"vector":[1,0.1]},
+			{"id":"id2","score":0.5,"vector":[0,1]}
+		]}`))
+	}))
+	defer server.Close()
+
+	index := NewIndex(server.URL, "token")
+	ids := func(lambda float32) []string {
+		scores, err := index.QueryMMR(QueryMMR{Vector: []float32{1, 0}, TopK: 2, Lambda: &lambda})
+		require.NoError(t, err)
+		var ids []string
+		for _, score := range scores {
+			ids = append(ids, score.Id)
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"id0", "id1"}, ids(1))
+
+	// Pure diversity is not replaced with the default lambda.
+	require.Equal(t, []string{"id0", "id2"}, ids(0))
+}
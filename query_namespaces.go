@@ -0,0 +1,135 @@
+package vector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NamespacesError is returned from the operations run over multiple
+// namespaces, when the operation fails for some of the namespaces.
+type NamespacesError struct {
+	// Errors that occurred, keyed by the namespace names.
+	Errors map[string]error
+}
+
+func (e *NamespacesError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for ns := range e.Errors {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed for %d namespace(s):", len(names))
+	for _, ns := range names {
+		fmt.Fprintf(&b, " %q: %v;", ns, e.Errors[ns])
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func (e *NamespacesError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// QueryNamespaces runs the query for the given vector concurrently in the given namespaces,
+// or in all the namespaces of the index if none is given, and merges the results.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors in total.
+// The returned list will contain vectors sorted in descending order of score,
+// and the Namespace field of each vector is set to the namespace it belongs to.
+// If the query fails for some of the namespaces, the merged results of the
+// successful ones are returned along with a *NamespacesError.
+func (ix *Index) QueryNamespaces(q Query, namespaces ...string) (scores []VectorScore, err error) {
+	return ix.queryNamespacesInternal(namespaces, resultLimit(q.TopK, q.MaxResults), func(ns string) ([]VectorScore, error) {
+		return ix.queryInternal(q, ns)
+	})
+}
+
+// QueryDataNamespaces runs the query for the given data concurrently in the given namespaces,
+// or in all the namespaces of the index if none is given, and merges the results.
+// The data is converted to an embedding on the server.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors in total.
+// The returned list will contain vectors sorted in descending order of score,
+// and the Namespace field of each vector is set to the namespace it belongs to.
+// If the query fails for some of the namespaces, the merged results of the
+// successful ones are returned along with a *NamespacesError.
+func (ix *Index) QueryDataNamespaces(q QueryData, namespaces ...string) (scores []VectorScore, err error) {
+	return ix.queryNamespacesInternal(namespaces, resultLimit(q.TopK, q.MaxResults), func(ns string) ([]VectorScore, error) {
+		return ix.queryDataInternal(q, ns)
+	})
+}
+
+func (ix *Index) queryNamespacesInternal(namespaces []string, limit int, query func(ns string) ([]VectorScore, error)) (scores []VectorScore, err error) {
+	if len(namespaces) == 0 {
+		if namespaces, err = ix.ListNamespaces(); err != nil {
+			return
+		}
+	}
+
+	results := make([][]VectorScore, len(namespaces))
+	errs := make([]error, len(namespaces))
+
+	var wg sync.WaitGroup
+	for i, ns := range namespaces {
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer wg.Done()
+			res, err := query(ns)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for j := range res {
+				res[j].Namespace = ns
+			}
+			results[i] = res
+		}(i, ns)
+	}
+	wg.Wait()
+
+	var nsErr *NamespacesError
+	for i, e := range errs {
+		if e == nil {
+			continue
+		}
+		if nsErr == nil {
+			nsErr = &NamespacesError{Errors: map[string]error{}}
+		}
+		nsErr.Errors[namespaces[i]] = e
+	}
+
+	scores = mergeScores(results, limit)
+	if nsErr != nil {
+		err = nsErr
+	}
+	return
+}
+
+// mergeScores merges the given lists of scores into a single list,
+// sorted in descending order of score, with at most topK elements
+// when topK is positive.
+func mergeScores(lists [][]VectorScore, topK int) []VectorScore {
+	var n int
+	for _, l := range lists {
+		n += len(l)
+	}
+
+	merged := make([]VectorScore, 0, n)
+	for _, l := range lists {
+		merged = append(merged, l...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+
+	if topK > 0 && len(merged) > topK {
+		merged = merged[:topK]
+	}
+	return merged
+}
@@ -0,0 +1,82 @@
+package vector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryNamespaces(t *testing.T) {
+	client, err := newTestClient(testClientTypeDense, defaultNamespace)
+	require.NoError(t, err)
+
+	index := client.index
+
+	id0 := randomString()
+	err = index.Upsert(Upsert{
+		Id:     id0,
+		Vector: []float32{0.6, 0.8},
+	})
+	require.NoError(t, err)
+
+	id1 := randomString()
+	err = index.Namespace("ns").Upsert(Upsert{
+		Id:     id1,
+		Vector: []float32{0.61, 0.8},
+	})
+	require.NoError(t, err)
+
+	id2 := randomString()
+	err = index.Namespace("ns").Upsert(Upsert{
+		Id:     id2,
+		Vector: []float32{0.8, 0.6},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		info, err := index.Info()
+		require.NoError(t, err)
+		return info.PendingVectorCount == 0
+	}, 10*time.Second, 1*time.Second)
+
+	t.Run("given namespaces", func(t *testing.T) {
+		scores, err := index.QueryNamespaces(Query{
+			Vector: []float32{0.6, 0.8},
+			TopK:   2,
+		}, defaultNamespace, "ns")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(scores))
+		require.Equal(t, id0, scores[0].Id)
+		require.Equal(t, defaultNamespace, scores[0].Namespace)
+		require.Equal(t, id1, scores[1].Id)
+		require.Equal(t, "ns", scores[1].Namespace)
+	})
+
+	t.Run("all namespaces", func(t *testing.T) {
+		scores, err := index.QueryNamespaces(Query{
+			Vector: []float32{0.6, 0.8},
+			TopK:   10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, len(scores))
+		require.Equal(t, id0, scores[0].Id)
+		require.Equal(t, id1, scores[1].Id)
+		require.Equal(t, id2, scores[2].Id)
+		require.Equal(t, "ns", scores[2].Namespace)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		scores, err := index.QueryNamespaces(Query{
+			Vector: []float32{0.6, 0.8, 0.1},
+			TopK:   2,
+		}, "ns")
+		require.Error(t, err)
+		require.Empty(t, scores)
+
+		var nsErr *NamespacesError
+		require.True(t, errors.As(err, &nsErr))
+		require.Contains(t, nsErr.Errors, "ns")
+	})
+}
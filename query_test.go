@@ -1,6 +1,8 @@
 package vector
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -273,3 +275,117 @@ func TestQueryFusionAlgorithm(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryMinScore(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			client, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			err = client.UpsertMany([]Upsert{
+				{Id: "id0", Vector: []float32{1, 0}},
+				{Id: "id1", Vector: []float32{0.9, 0.1}},
+				{Id: "id2", Vector: []float32{0, 1}},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			minScore := float32(0.9)
+
+			t.Run("min score", func(t *testing.T) {
+				scores, err := client.Query(Query{
+					Vector:   []float32{1, 0},
+					TopK:     3,
+					MinScore: &minScore,
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, len(scores))
+				require.Equal(t, "id0", scores[0].Id)
+				require.Equal(t, "id1", scores[1].Id)
+			})
+
+			t.Run("max results", func(t *testing.T) {
+				scores, err := client.Query(Query{
+					Vector:     []float32{1, 0},
+					TopK:       3,
+					MinScore:   &minScore,
+					MaxResults: 1,
+				})
+				require.NoError(t, err)
+				require.Equal(t, 1, len(scores))
+				require.Equal(t, "id0", scores[0].Id)
+			})
+		})
+	}
+}
+
+func TestFilterScores(t *testing.T) {
+	scores := []VectorScore{
+		{Id: "id0", Score: 0.9},
+		{Id: "id1", Score: 0.8},
+		{Id: "id2", Score: 0.7},
+	}
+
+	score := func(v float32) *float32 {
+		return &v
+	}
+
+	filtered, dropped := filterScores(scores, nil, 0)
+	require.Equal(t, scores, filtered)
+	require.False(t, dropped)
+
+	filtered, dropped = filterScores(scores, score(0.75), 0)
+	require.Equal(t, scores[:2], filtered)
+	require.True(t, dropped)
+
+	filtered, dropped = filterScores(scores, score(0.75), 1)
+	require.Equal(t, scores[:1], filtered)
+	require.True(t, dropped)
+
+	filtered, dropped = filterScores(scores, score(0.5), 2)
+	require.Equal(t, scores[:2], filtered)
+	require.False(t, dropped)
+
+	require.Equal(t, 5, resultLimit(10, 5))
+	require.Equal(t, 5, resultLimit(0, 5))
+	require.Equal(t, 3, resultLimit(3, 5))
+	require.Equal(t, 3, resultLimit(3, 0))
+	require.Equal(t, 0, resultLimit(0, 0))
+}
+
+func TestQueryNegativeScores(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == resumableQueryPath {
+			_, _ = w.Write([]byte(`{"result":{"uuid":"u","scores":[{"id":"a","score":0.3},{"id":"b","score":-0.2}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[{"id":"a","score":0.3},{"id":"b","score":-0.2}]}`))
+	}))
+	defer server.Close()
+
+	index := NewIndex(server.URL, "token")
+	expected := []VectorScore{{Id: "a", Score: 0.3}, {Id: "b", Score: -0.2}}
+
+	// Negative scores are kept when MinScore is not set.
+	scores, err := index.Query(Query{Vector: []float32{1, 0}, TopK: 2})
+	require.NoError(t, err)
+	require.Equal(t, expected, scores)
+
+	scores, err = index.QueryData(QueryData{Data: "data", TopK: 2})
+	require.NoError(t, err)
+	require.Equal(t, expected, scores)
+
+	scores, _, err = index.ResumableQuery(ResumableQuery{Vector: []float32{1, 0}, TopK: 2})
+	require.NoError(t, err)
+	require.Equal(t, expected, scores)
+
+	minScore := float32(0)
+	scores, err = index.Query(Query{Vector: []float32{1, 0}, TopK: 2, MinScore: &minScore})
+	require.NoError(t, err)
+	require.Equal(t, expected[:1], scores)
+}
@@ -1,6 +1,9 @@
 package vector
 
-const rangePath = "/range"
+const (
+	rangePath                 = "/range"
+	defaultRangeIteratorLimit = 1000
+)
 
 // Range returns a range of vectors, starting with r.Cursor (inclusive),
 // until the end of the vectors in the index or until the given q.Limit.
@@ -17,6 +20,112 @@ func (ix *Index) rangeInternal(r Range, ns string) (vectors RangeVectors, err er
 	if err != nil {
 		return
 	}
-	vectors, err = parseResponse[RangeVectors](data)
+	vectors, err = parseRangeVectors(data)
 	return
 }
+
+// RangeIterator returns an iterator over the vectors in the default namespace,
+// starting with r.Cursor (inclusive), until the end of the vectors in the index.
+// If r.Cursor is not provided, the iteration starts from the beginning.
+// The pages of vectors are requested with r.Limit, which defaults to 1000,
+// and the vectors are decoded as they are read from the responses, which
+// avoids holding whole pages in memory while ranging with r.IncludeVectors.
+// The first page is requested on the first call to Next.
+func (ix *Index) RangeIterator(r Range) *RangeIterator {
+	return ix.rangeIteratorInternal(r, defaultNamespace)
+}
+
+func (ix *Index) rangeIteratorInternal(r Range, ns string) *RangeIterator {
+	if r.Cursor == "" {
+		r.Cursor = "0"
+	}
+	if r.Limit <= 0 {
+		r.Limit = defaultRangeIteratorLimit
+	}
+	return &RangeIterator{
+		index: ix,
+		ns:    ns,
+		r:     r,
+	}
+}
+
+// RangeIterator iterates over the vectors of a namespace page by page.
+//
+// The iterator should be closed after it is used, to release the
+// underlying connection.
+//
+//	it := index.RangeIterator(vector.Range{IncludeVectors: true})
+//	defer it.Close()
+//	for it.Next() {
+//		v := it.Vector()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type RangeIterator struct {
+	index  *Index
+	ns     string
+	r      Range
+	stream *vectorStream
+	vector Vector
+	err    error
+	done   bool
+}
+
+// Next advances the iterator to the next vector, which is then
+// available through Vector. It returns false when there are no
+// more vectors or an error occurs, which is reported by Err.
+func (it *RangeIterator) Next() bool {
+	for it.err == nil && !it.done {
+		if it.stream == nil {
+			it.stream, it.err = it.index.openVectorStream(buildPath(rangePath, it.ns), it.r, true)
+			if it.err != nil {
+				return false
+			}
+		}
+
+		var ok bool
+		if ok, it.err = it.stream.next(&it.vector); ok {
+			return true
+		}
+
+		it.stream.close()
+		if it.err != nil {
+			return false
+		}
+
+		it.r.Cursor = it.stream.nextCursor
+		it.stream = nil
+		it.done = it.r.Cursor == ""
+	}
+	return false
+}
+
+// Vector returns the current vector.
+func (it *RangeIterator) Vector() Vector {
+	return it.vector
+}
+
+// Cursor returns the cursor of the page of the current vector, which can
+// be used to resume the iteration from the start of that page later.
+// It returns an empty string when the iteration is completed.
+func (it *RangeIterator) Cursor() string {
+	if it.done {
+		return ""
+	}
+	return it.r.Cursor
+}
+
+// Err returns the error that occurred during the iteration, if any.
+func (it *RangeIterator) Err() error {
+	return it.err
+}
+
+// Close closes the iterator.
+func (it *RangeIterator) Close() error {
+	it.done = true
+	if it.stream != nil {
+		return it.stream.close()
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request cannot be sent within
+// the MaxWait of the rate limits, without sending the request.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// writeOperations are the operations that modify the index.
+// The other operations are treated as reads.
+var writeOperations = map[string]bool{
+	"upsert":           true,
+	"upsert-data":      true,
+	"update":           true,
+	"delete":           true,
+	"reset":            true,
+	"delete-namespace": true,
+}
+
+// RateLimitOptions configures the client side limits of the requests sent
+// to the index, with separate budgets for the reads and the writes.
+//
+// The limits are shared by all the namespaces of the index, and the callers
+// wait until their requests can be sent, rather than sending requests that
+// would be rejected by the server.
+type RateLimitOptions struct {
+	// Limits of the queries, fetches, ranges and the other read requests.
+	Reads RequestLimit
+
+	// Limits of the upserts, updates, deletes and resets.
+	Writes RequestLimit
+
+	// Maximum duration a request waits to be sent. Requests that cannot be
+	// sent within it fail with ErrRateLimited.
+	// If not provided, the requests wait as long as needed.
+	MaxWait time.Duration
+}
+
+// RequestLimit limits the rate and the concurrency of the requests.
+type RequestLimit struct {
+	// Maximum number of requests per second.
+	// If not provided, the rate is not limited.
+	RequestsPerSecond float64
+
+	// Maximum number of requests that can be sent at once after the
+	// requests are idle for a while.
+	// If not provided, defaults to RequestsPerSecond, rounded up.
+	Burst int
+
+	// Maximum number of requests in flight. A request is in flight until
+	// its response is read, or its iterator is closed for the streams.
+	// If not provided, the concurrency is not limited.
+	MaxInFlight int
+}
+
+type rateLimiter struct {
+	reads   *requestLimiter
+	writes  *requestLimiter
+	maxWait time.Duration
+}
+
+func newRateLimiter(options RateLimitOptions) *rateLimiter {
+	return &rateLimiter{
+		reads:   newRequestLimiter(options.Reads),
+		writes:  newRequestLimiter(options.Writes),
+		maxWait: options.MaxWait,
+	}
+}
+
+// acquire waits until a request with the given operation can be sent, and
+// returns the function to call once the response of the request is read.
+// It stops waiting with the error of the context once it is done, such as
+// when a hedged request is no longer needed.
+func (l *rateLimiter) acquire(ctx context.Context, operation string) (done func(), err error) {
+	limiter := l.reads
+	if writeOperations[operation] {
+		limiter = l.writes
+	}
+
+	var deadline time.Time
+	if l.maxWait > 0 {
+		deadline = time.Now().Add(l.maxWait)
+	}
+	return limiter.acquire(ctx, deadline)
+}
+
+type requestLimiter struct {
+	// Token bucket of the rate limit, nil if the rate is not limited.
+	bucket *tokenBucket
+
+	// Semaphore of the in-flight requests, nil if the concurrency is not limited.
+	inFlight chan struct{}
+}
+
+func newRequestLimiter(limit RequestLimit) *requestLimiter {
+	l := &requestLimiter{}
+	if limit.RequestsPerSecond > 0 {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(limit.RequestsPerSecond))
+		}
+		l.bucket = newTokenBucket(limit.RequestsPerSecond, burst)
+	}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// acquire waits for a slot of the in-flight requests and then for a token of
+// the rate limit, so that the tokens are not spent by the waiting requests.
+func (l *requestLimiter) acquire(ctx context.Context, deadline time.Time) (done func(), err error) {
+	done = func() {}
+	if l.inFlight != nil {
+		if err = l.wait(ctx, deadline); err != nil {
+			return
+		}
+		var once sync.Once
+		done = func() {
+			once.Do(func() { <-l.inFlight })
+		}
+	}
+
+	if l.bucket != nil {
+		if err = l.bucket.wait(ctx, deadline); err != nil {
+			done()
+			return nil, err
+		}
+	}
+	return
+}
+
+func (l *requestLimiter) wait(ctx context.Context, deadline time.Time) error {
+	select {
+	case l.inFlight <- struct{}{}:
+		return nil
+	default:
+	}
+
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+		return nil
+	case <-expired:
+		return ErrRateLimited
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tokenBucket allows the requests at the given rate, with bursts up to
+// the size of the bucket. The tokens are reserved before waiting for them,
+// so that the waiting requests are sent in order.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	size   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		size:   float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token from the bucket, waiting for it if there is none.
+// If the token cannot be taken before the deadline, it returns
+// ErrRateLimited without taking the token. If the context is done
+// while waiting, the token is given back and the error of the
+// context is returned.
+func (b *tokenBucket) wait(ctx context.Context, deadline time.Time) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.size, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	if !deadline.IsZero() && now.Add(delay).After(deadline) {
+		b.mu.Unlock()
+		return ErrRateLimited
+	}
+	b.tokens--
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens = min(b.size, b.tokens+1)
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// limitedBody is a response body that completes the
+// request for the rate limiter when it is closed.
+type limitedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *limitedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
@@ -0,0 +1,145 @@
+package vector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if r.URL.Path == resetPath {
+			_, _ = w.Write([]byte(`{"result":"Success"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	t.Run("in flight", func(t *testing.T) {
+		maxInFlight.Store(0)
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			RateLimit: &RateLimitOptions{
+				Reads: RequestLimit{MaxInFlight: 2},
+			},
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := index.Namespace("ns").Fetch(Fetch{Ids: []string{"a"}})
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(2), maxInFlight.Load())
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			RateLimit: &RateLimitOptions{
+				Writes: RequestLimit{RequestsPerSecond: 10, Burst: 1},
+			},
+		})
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+			require.NoError(t, err)
+		}
+		require.Less(t, time.Since(start), 150*time.Millisecond)
+
+		start = time.Now()
+		for i := 0; i < 3; i++ {
+			err := index.Reset()
+			require.NoError(t, err)
+		}
+		require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			RateLimit: &RateLimitOptions{
+				Reads:   RequestLimit{RequestsPerSecond: 1},
+				MaxWait: 10 * time.Millisecond,
+			},
+		})
+
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+
+		_, err = index.Fetch(Fetch{Ids: []string{"a"}})
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		limiter := newRateLimiter(RateLimitOptions{
+			Reads:   RequestLimit{RequestsPerSecond: 1, MaxInFlight: 1},
+			MaxWait: 1500 * time.Millisecond,
+		})
+
+		done, err := limiter.acquire(context.Background(), "fetch")
+		require.NoError(t, err)
+
+		// The slot of the in-flight requests is still used.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.acquire(ctx, "fetch")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		done()
+
+		// The token is given back when the wait is canceled, so that the
+		// next request does not wait for it too.
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.acquire(ctx, "fetch")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		done, err = limiter.acquire(context.Background(), "fetch")
+		require.NoError(t, err)
+		done()
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		index := NewIndexWith(Options{
+			Url:   server.URL,
+			Token: "token",
+			RateLimit: &RateLimitOptions{
+				Reads:   RequestLimit{MaxInFlight: 1},
+				MaxWait: 100 * time.Millisecond,
+			},
+		})
+
+		it := index.FetchIterator(Fetch{Ids: []string{"a"}})
+		require.False(t, it.Next())
+		require.NoError(t, it.Err())
+		require.NoError(t, it.Close())
+
+		_, err := index.Fetch(Fetch{Ids: []string{"a"}})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+package vector
+
+import "fmt"
+
+// ReadOnlyError is returned for the operations that modify the index,
+// such as upserts, updates, deletes and resets, when the index client
+// is read-only. The requests of such operations are not sent.
+type ReadOnlyError struct {
+	// Name of the rejected operation, such as "upsert" or "delete-namespace".
+	Operation string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("index client is read-only, %s is not allowed", e.Operation)
+}
+
+// checkWritable returns a *ReadOnlyError if the operation modifies the index
+// and the index client is read-only. It is checked for all the requests, and
+// also before validating the upserts and updates, which might fetch the index info.
+func (ix *Index) checkWritable(operation string) error {
+	if ix.readOnly && writeOperations[operation] {
+		return &ReadOnlyError{Operation: operation}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package vector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == infoPath {
+			_, _ = w.Write([]byte(`{"result":{"dimension":2,"similarityFunction":"COSINE"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	index := NewIndexWith(Options{
+		Url:      server.URL,
+		Token:    "token",
+		Validate: true,
+		ReadOnly: true,
+	})
+	ns := index.Namespace("ns")
+
+	writes := map[string]func() error{
+		"upsert": func() error {
+			return index.Upsert(Upsert{Id: "a", Vector: []float32{0.1, 0.2}})
+		},
+		"upsert many": func() error {
+			return ns.UpsertMany([]Upsert{{Id: "a", Vector: []float32{0.1, 0.2}}})
+		},
+		"upsert data": func() error {
+			return ns.UpsertData(UpsertData{Id: "a", Data: "data"})
+		},
+		"update": func() error {
+			_, err := index.Update(Update{Id: "a", Vector: []float32{0.1, 0.2}})
+			return err
+		},
+		"delete": func() error {
+			_, err := ns.Delete("a")
+			return err
+		},
+		"delete many": func() error {
+			_, err := index.DeleteMany([]string{"a"})
+			return err
+		},
+		"reset": func() error {
+			return index.Reset()
+		},
+		"delete namespace": func() error {
+			return ns.DeleteNamespace()
+		},
+	}
+	for name, write := range writes {
+		err := write()
+		var readOnlyErr *ReadOnlyError
+		require.True(t, errors.As(err, &readOnlyErr), name)
+	}
+	require.Empty(t, paths)
+
+	_, err := ns.Fetch(Fetch{Ids: []string{"a"}})
+	require.NoError(t, err)
+	_, err = index.Query(Query{Vector: []float32{0.1, 0.2}})
+	require.NoError(t, err)
+	_, err = index.ListNamespaces()
+	require.NoError(t, err)
+	require.Equal(t, []string{fetchPath + "/ns", infoPath, queryPath, listNamespacesPath}, paths)
+}
@@ -0,0 +1,243 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultRecommendTopK           = 10
+	defaultRecommendPositiveWeight = 1
+	defaultRecommendNegativeWeight = 0.5
+)
+
+// Recommend returns the vectors in the default namespace that are similar to the
+// positive examples and dissimilar to the negative examples.
+// The query vector is calculated on the client as the weighted average of the positive
+// examples minus the weighted average of the negative examples, similar to the Rocchio
+// algorithm. For sparse vectors, dimensions with non-positive values are dropped
+// from the query vector.
+// Values of the examples given only with ids are fetched from the index, and the vectors
+// with the ids of the examples are excluded from the result.
+// When r.TopK is specified, the result will contain at most r.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score.
+// When r.IncludeVectors is true, values of the vectors are also returned.
+// When r.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ix *Index) Recommend(r Recommend) (scores []VectorScore, err error) {
+	return ix.recommendInternal(r, defaultNamespace)
+}
+
+// Recommend returns the vectors in the namespace that are similar to the
+// positive examples and dissimilar to the negative examples.
+// See Index.Recommend for details.
+func (ns *Namespace) Recommend(r Recommend) (scores []VectorScore, err error) {
+	return ns.index.recommendInternal(r, ns.ns)
+}
+
+func (ix *Index) recommendInternal(r Recommend, ns string) (scores []VectorScore, err error) {
+	if len(r.Positive) == 0 {
+		err = errors.New("at least one positive example is required")
+		return
+	}
+
+	positive, negative, err := ix.fetchExamples(r.Positive, r.Negative, ns)
+	if err != nil {
+		return
+	}
+
+	positiveWeight := r.PositiveWeight
+	if positiveWeight == 0 {
+		positiveWeight = defaultRecommendPositiveWeight
+	}
+	negativeWeight := float32(defaultRecommendNegativeWeight)
+	if r.NegativeWeight != nil {
+		negativeWeight = *r.NegativeWeight
+	}
+
+	v, err := combineDense(positive, negative, positiveWeight, negativeWeight)
+	if err != nil {
+		return
+	}
+	sv := combineSparse(positive, negative, positiveWeight, negativeWeight)
+
+	topK := r.TopK
+	if topK <= 0 {
+		topK = defaultRecommendTopK
+	}
+
+	ids := map[string]struct{}{}
+	for _, examples := range [][]RecommendExample{r.Positive, r.Negative} {
+		for _, e := range examples {
+			if e.Id != "" {
+				ids[e.Id] = struct{}{}
+			}
+		}
+	}
+
+	res, err := ix.queryInternal(Query{
+		Vector:            v,
+		SparseVector:      sv,
+		TopK:              topK + len(ids),
+		IncludeVectors:    r.IncludeVectors,
+		IncludeMetadata:   r.IncludeMetadata,
+		IncludeData:       r.IncludeData,
+		Filter:            r.Filter,
+		WeightingStrategy: r.WeightingStrategy,
+		FusionAlgorithm:   r.FusionAlgorithm,
+	}, ns)
+	if err != nil {
+		return
+	}
+
+	scores = excludeScores(res, func(id string) bool {
+		_, ok := ids[id]
+		return ok
+	}, topK)
+	return
+}
+
+// fetchExamples checks the examples, and fills the values of
+// the examples given only with ids from the index.
+func (ix *Index) fetchExamples(positive []RecommendExample, negative []RecommendExample, ns string) ([]RecommendExample, []RecommendExample, error) {
+	var ids []string
+	for _, examples := range [][]RecommendExample{positive, negative} {
+		for _, e := range examples {
+			if sv := e.SparseVector; sv != nil && len(sv.Indices) != len(sv.Values) {
+				return nil, nil, fmt.Errorf("sparse vector of an example has %d indices but %d values", len(sv.Indices), len(sv.Values))
+			}
+			if e.Vector == nil && e.SparseVector == nil {
+				if e.Id == "" {
+					return nil, nil, errors.New("examples must have an id or vector values")
+				}
+				ids = append(ids, e.Id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return positive, negative, nil
+	}
+
+	vectors, err := ix.fetchInternal(Fetch{
+		Ids:            ids,
+		IncludeVectors: true,
+	}, ns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fetched := make(map[string]Vector, len(vectors))
+	for _, v := range vectors {
+		if v.Id != "" {
+			fetched[v.Id] = v
+		}
+	}
+
+	fill := func(examples []RecommendExample) ([]RecommendExample, error) {
+		filled := make([]RecommendExample, len(examples))
+		for i, e := range examples {
+			if e.Vector == nil && e.SparseVector == nil {
+				v, ok := fetched[e.Id]
+				if !ok {
+					return nil, fmt.Errorf("%w: %s", ErrVectorNotFound, e.Id)
+				}
+				e.Vector, e.SparseVector = v.Vector, v.SparseVector
+			}
+			filled[i] = e
+		}
+		return filled, nil
+	}
+
+	if positive, err = fill(positive); err != nil {
+		return nil, nil, err
+	}
+	if negative, err = fill(negative); err != nil {
+		return nil, nil, err
+	}
+	return positive, negative, nil
+}
+
+// combineDense returns the weighted average of the dense vectors of the positive examples
+// minus the weighted average of the dense vectors of the negative examples.
+func combineDense(positive []RecommendExample, negative []RecommendExample, positiveWeight float32, negativeWeight float32) ([]float32, error) {
+	var combined []float32
+	add := func(examples []RecommendExample, weight float32) error {
+		var n int
+		for _, e := range examples {
+			if e.Vector != nil {
+				n++
+			}
+		}
+		for _, e := range examples {
+			if e.Vector == nil {
+				continue
+			}
+			if combined == nil {
+				combined = make([]float32, len(e.Vector))
+			}
+			if len(e.Vector) != len(combined) {
+				return fmt.Errorf("vector dimensions of the examples do not match: %d != %d", len(e.Vector), len(combined))
+			}
+			for i, value := range e.Vector {
+				combined[i] += weight * value / float32(n)
+			}
+		}
+		return nil
+	}
+
+	if err := add(positive, positiveWeight); err != nil {
+		return nil, err
+	}
+	if err := add(negative, -negativeWeight); err != nil {
+		return nil, err
+	}
+	return combined, nil
+}
+
+// combineSparse returns the weighted average of the sparse vectors of the positive examples
+// minus the weighted average of the sparse vectors of the negative examples, without the
+// dimensions with non-positive values.
+func combineSparse(positive []RecommendExample, negative []RecommendExample, positiveWeight float32, negativeWeight float32) *SparseVector {
+	values := map[int32]float32{}
+	add := func(examples []RecommendExample, weight float32) {
+		var n int
+		for _, e := range examples {
+			if e.SparseVector != nil {
+				n++
+			}
+		}
+		for _, e := range examples {
+			if e.SparseVector == nil {
+				continue
+			}
+			for i, index := range e.SparseVector.Indices {
+				values[index] += weight * e.SparseVector.Values[i] / float32(n)
+			}
+		}
+	}
+
+	add(positive, positiveWeight)
+	add(negative, -negativeWeight)
+
+	indices := make([]int32, 0, len(values))
+	for index, value := range values {
+		if value > 0 {
+			indices = append(indices, index)
+		}
+	}
+	if len(indices) == 0 {
+		return nil
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+
+	sv := &SparseVector{
+		Indices: indices,
+		Values:  make([]float32, len(indices)),
+	}
+	for i, index := range indices {
+		sv.Values[i] = values[index]
+	}
+	return sv
+}
@@ -0,0 +1,135 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineExamples(t *testing.T) {
+	positive := []RecommendExample{
+		{Vector: []float32{1, 0}, SparseVector: &SparseVector{Indices: []int32{0}, Values: []float32{1}}},
+		{Vector: []float32{0, 1}, SparseVector: &SparseVector{Indices: []int32{1}, Values: []float32{1}}},
+	}
+	negative := []RecommendExample{
+		{Vector: []float32{0, 1}, SparseVector: &SparseVector{Indices: []int32{1, 2}, Values: []float32{2, 1}}},
+	}
+
+	v, err := combineDense(positive, negative, 1, 0.5)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.5, 0}, v)
+
+	sv := combineSparse(positive, negative, 1, 0.5)
+	require.Equal(t, &SparseVector{Indices: []int32{0}, Values: []float32{0.5}}, sv)
+
+	_, err = combineDense(positive, []RecommendExample{{Vector: []float32{1}}}, 1, 0.5)
+	require.Error(t, err)
+
+	v, err = combineDense([]RecommendExample{{SparseVector: &SparseVector{}}}, nil, 1, 0.5)
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestRecommendExamples(t *testing.T) {
+	var query Query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		_, _ = w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	index := NewIndex(server.URL, "token")
+	positive := []RecommendExample{{Vector: []float32{1, 0}}}
+	negative := []RecommendExample{{Vector: []float32{0, 1}}}
+
+	_, err := index.Recommend(Recommend{Positive: positive, Negative: negative})
+	require.NoError(t, err)
+	require.Equal(t, []float32{1, -0.5}, query.Vector)
+
+	// The negative examples are ignored with a weight of zero,
+	// instead of the default weight.
+	weight := float32(0)
+	_, err = index.Recommend(Recommend{Positive: positive, Negative: negative, NegativeWeight: &weight})
+	require.NoError(t, err)
+	require.Equal(t, []float32{1, 0}, query.Vector)
+
+	_, err = index.Recommend(Recommend{
+		Positive: positive,
+		Negative: []RecommendExample{{SparseVector: &SparseVector{Indices: []int32{0, 1}, Values: []float32{1}}}},
+	})
+	require.EqualError(t, err, "sparse vector of an example has 2 indices but 1 values")
+}
+
+func TestRecommend(t *testing.T) {
+	for _, ns := range namespaces {
+		for _, tcType := range testClientTypes {
+			t.Run("namespace_"+ns+"_index_type_"+string(tcType), func(t *testing.T) {
+				client, err := newTestClient(tcType, ns)
+				require.NoError(t, err)
+
+				upsert := func(id string, v []float32, sv *SparseVector) Upsert {
+					switch tcType {
+					case testClientTypeDense:
+						return Upsert{Id: id, Vector: v}
+					case testClientTypeSparse:
+						return Upsert{Id: id, SparseVector: sv}
+					default:
+						return Upsert{Id: id, Vector: v, SparseVector: sv}
+					}
+				}
+
+				err = client.UpsertMany([]Upsert{
+					upsert("a", []float32{1, 0}, &SparseVector{Indices: []int32{0}, Values: []float32{1}}),
+					upsert("b", []float32{0.9, 0.1}, &SparseVector{Indices: []int32{0, 1}, Values: []float32{0.9, 0.1}}),
+					upsert("c", []float32{0, 1}, &SparseVector{Indices: []int32{1}, Values: []float32{1}}),
+					upsert("d", []float32{0.7, 0.7}, &SparseVector{Indices: []int32{0, 1}, Values: []float32{0.5, 0.5}}),
+				})
+				require.NoError(t, err)
+
+				require.Eventually(t, func() bool {
+					info, err := client.Info()
+					require.NoError(t, err)
+					return info.PendingVectorCount == 0
+				}, 10*time.Second, 1*time.Second)
+
+				t.Run("ids", func(t *testing.T) {
+					scores, err := client.Recommend(Recommend{
+						Positive: []RecommendExample{{Id: "a"}},
+						Negative: []RecommendExample{{Id: "c"}},
+						TopK:     2,
+					})
+					require.NoError(t, err)
+					require.Equal(t, 2, len(scores))
+					require.Equal(t, "b", scores[0].Id)
+					require.Equal(t, "d", scores[1].Id)
+				})
+
+				t.Run("vectors", func(t *testing.T) {
+					example := upsert("", []float32{0, 1}, &SparseVector{Indices: []int32{1}, Values: []float32{1}})
+					scores, err := client.Recommend(Recommend{
+						Positive: []RecommendExample{{
+							Vector:       example.Vector,
+							SparseVector: example.SparseVector,
+						}},
+						TopK: 1,
+					})
+					require.NoError(t, err)
+					require.Equal(t, 1, len(scores))
+					require.Equal(t, "c", scores[0].Id)
+				})
+
+				t.Run("non existing id", func(t *testing.T) {
+					_, err := client.Recommend(Recommend{
+						Positive: []RecommendExample{{Id: randomString()}},
+					})
+					require.True(t, errors.Is(err, ErrVectorNotFound))
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package vector
+
+import "fmt"
+
+const defaultReembedBatchSize = 100
+
+// Embedding is the dense and sparse vector representation of a raw data.
+type Embedding struct {
+	// Dense vector values for dense and hybrid indexes.
+	Vector []float32
+
+	// Sparse vector values for sparse and hybrid indexes.
+	SparseVector *SparseVector
+}
+
+// Embedder converts raw data into embeddings on the client.
+type Embedder interface {
+	// Embed returns the embeddings of the given data,
+	// in the same order as the data.
+	Embed(data []string) ([]Embedding, error)
+}
+
+// EmbedderFunc is an adapter to use ordinary functions as Embedder.
+type EmbedderFunc func(data []string) ([]Embedding, error)
+
+// Embed calls f(data).
+func (f EmbedderFunc) Embed(data []string) ([]Embedding, error) {
+	return f(data)
+}
+
+// Reembed is a job that reads all the vectors of a namespace, and upserts
+// them into another namespace, possibly of another index, with new
+// embeddings of their data. Ids and metadata of the vectors are preserved.
+type Reembed struct {
+	// Namespace to read the vectors from.
+	Source *Namespace
+
+	// Namespace to upsert the re-embedded vectors to.
+	Target *Namespace
+
+	// Optional embedder to convert the data to embeddings on the client.
+	// If not provided, the data is upserted to the target with UpsertDataMany,
+	// and converted to embeddings on the server.
+	Embedder Embedder
+
+	// The number of vectors read and upserted at once.
+	// If not provided, defaults to 100.
+	BatchSize int
+
+	// The cursor to start reading the source from, which can be used
+	// to continue from the last checkpoint.
+	// If not provided, starts from the beginning.
+	Cursor string
+
+	// Optional function that is called with the cursor of the next batch
+	// after each batch is upserted to the target. The cursor is empty
+	// after the last batch.
+	Checkpoint func(cursor string) error
+}
+
+// ReembedResult is the summary of a Reembed run.
+type ReembedResult struct {
+	// The number of vectors upserted to the target.
+	Reembedded int
+
+	// Ids of the vectors that are skipped as they have no data.
+	MissingData []string
+
+	// The cursor of the first batch that is not completed.
+	// It is empty when all the vectors are processed.
+	Cursor string
+}
+
+// Run reads the vectors of the source in batches and upserts them to the
+// target. When an error occurs, the result contains the cursor that the
+// job can be continued from.
+func (r *Reembed) Run() (result ReembedResult, err error) {
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReembedBatchSize
+	}
+
+	result.Cursor = r.Cursor
+	if result.Cursor == "" {
+		result.Cursor = "0"
+	}
+
+	for {
+		var page RangeVectors
+		page, err = r.Source.Range(Range{
+			Cursor:          result.Cursor,
+			Limit:           batchSize,
+			IncludeMetadata: true,
+			IncludeData:     true,
+		})
+		if err != nil {
+			return
+		}
+
+		var withData []Vector
+		for _, v := range page.Vectors {
+			if v.Data == "" {
+				result.MissingData = append(result.MissingData, v.Id)
+				continue
+			}
+			withData = append(withData, v)
+		}
+
+		if len(withData) > 0 {
+			if err = r.upsert(withData); err != nil {
+				return
+			}
+			result.Reembedded += len(withData)
+		}
+
+		result.Cursor = page.NextCursor
+		if r.Checkpoint != nil {
+			if err = r.Checkpoint(result.Cursor); err != nil {
+				return
+			}
+		}
+
+		if result.Cursor == "" {
+			return
+		}
+	}
+}
+
+func (r *Reembed) upsert(vectors []Vector) error {
+	if r.Embedder == nil {
+		u := make([]UpsertData, len(vectors))
+		for i, v := range vectors {
+			u[i] = UpsertData{
+				Id:       v.Id,
+				Data:     v.Data,
+				Metadata: v.Metadata,
+			}
+		}
+		return r.Target.UpsertDataMany(u)
+	}
+
+	data := make([]string, len(vectors))
+	for i, v := range vectors {
+		data[i] = v.Data
+	}
+
+	embeddings, err := r.Embedder.Embed(data)
+	if err != nil {
+		return err
+	}
+	if len(embeddings) != len(vectors) {
+		return fmt.Errorf("embedder returned %d embeddings for %d data", len(embeddings), len(vectors))
+	}
+
+	u := make([]Upsert, len(vectors))
+	for i, v := range vectors {
+		u[i] = Upsert{
+			Id:           v.Id,
+			Vector:       embeddings[i].Vector,
+			SparseVector: embeddings[i].SparseVector,
+			Data:         v.Data,
+			Metadata:     v.Metadata,
+		}
+	}
+	return r.Target.UpsertMany(u)
+}
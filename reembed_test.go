@@ -0,0 +1,103 @@
+package vector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReembed(t *testing.T) {
+	source, err := newTestClient(testClientTypeDense, defaultNamespace)
+	require.NoError(t, err)
+
+	id0 := randomString()
+	id1 := randomString()
+	id2 := randomString()
+	err = source.UpsertMany([]Upsert{
+		{
+			Id:       id0,
+			Vector:   []float32{0.1, 0.2},
+			Data:     "Capital of Japan is Tokyo.",
+			Metadata: map[string]any{"country": "jp"},
+		},
+		{
+			Id:     id1,
+			Vector: []float32{0.3, 0.4},
+			Data:   "Capital of France is Paris.",
+		},
+		{
+			Id:     id2,
+			Vector: []float32{0.5, 0.6},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("with embedder", func(t *testing.T) {
+		target := source.index.Namespace("ns")
+
+		var checkpoints []string
+		job := Reembed{
+			Source: source.index.Namespace(defaultNamespace),
+			Target: target,
+			Embedder: EmbedderFunc(func(data []string) ([]Embedding, error) {
+				embeddings := make([]Embedding, len(data))
+				for i, d := range data {
+					embeddings[i] = Embedding{Vector: []float32{float32(len(d)), 1}}
+				}
+				return embeddings, nil
+			}),
+			BatchSize: 2,
+			Checkpoint: func(cursor string) error {
+				checkpoints = append(checkpoints, cursor)
+				return nil
+			},
+		}
+
+		result, err := job.Run()
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Reembedded)
+		require.Equal(t, []string{id2}, result.MissingData)
+		require.Empty(t, result.Cursor)
+		require.Equal(t, 2, len(checkpoints))
+		require.Empty(t, checkpoints[1])
+
+		vectors, err := target.Fetch(Fetch{
+			Ids:             []string{id0, id1, id2},
+			IncludeVectors:  true,
+			IncludeMetadata: true,
+			IncludeData:     true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, id0, vectors[0].Id)
+		require.Equal(t, []float32{26, 1}, vectors[0].Vector)
+		require.Equal(t, map[string]any{"country": "jp"}, vectors[0].Metadata)
+		require.Equal(t, "Capital of Japan is Tokyo.", vectors[0].Data)
+		require.Equal(t, id1, vectors[1].Id)
+		require.Empty(t, vectors[2].Id)
+	})
+
+	t.Run("with server side embedding", func(t *testing.T) {
+		target, err := newTestClient(testClientTypeDenseEmbedding, defaultNamespace)
+		require.NoError(t, err)
+
+		job := Reembed{
+			Source: source.index.Namespace(defaultNamespace),
+			Target: target.index.Namespace(defaultNamespace),
+		}
+
+		result, err := job.Run()
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Reembedded)
+		require.Equal(t, []string{id2}, result.MissingData)
+
+		vectors, err := target.Fetch(Fetch{
+			Ids:             []string{id0, id1},
+			IncludeMetadata: true,
+			IncludeData:     true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, id0, vectors[0].Id)
+		require.Equal(t, map[string]any{"country": "jp"}, vectors[0].Metadata)
+		require.Equal(t, id1, vectors[1].Id)
+	})
+}
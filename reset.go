@@ -8,7 +8,9 @@ func (ix *Index) Reset() (err error) {
 }
 
 func (ix *Index) resetInternal(ns string) (err error) {
-	data, err := ix.send(buildPath(resetPath, ns), nil)
+	defer ix.invalidateQueryCache(ns)
+
+	data, err := ix.sendBytes(buildPath(resetPath, ns), nil)
 	if err != nil {
 		return
 	}
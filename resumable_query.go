@@ -7,12 +7,60 @@ const (
 )
 
 type ResumableQueryHandle struct {
-	index *Index
-	uuid  string
+	index    *Index
+	uuid     string
+	minScore *float32
+
+	// remaining is the number of vectors that can still be
+	// returned, or -1 if there is no limit.
+	remaining int
+
+	// exhausted is set when a vector with a score lower than
+	// minScore is seen, after which there is no need to fetch
+	// the next pages.
+	exhausted bool
 }
 
 // Next fetches the next page of the query result.
+// When the query has a minimum score, the next pages are fetched
+// until n.AdditionalK vectors with at least that score are gathered,
+// or a vector with a lower score is seen.
 func (h *ResumableQueryHandle) Next(n ResumableQueryNext) (scores []VectorScore, err error) {
+	if h.minScore == nil && h.remaining < 0 {
+		return h.next(n)
+	}
+
+	want := n.AdditionalK
+	if h.remaining >= 0 && (want <= 0 || want > h.remaining) {
+		want = h.remaining
+	}
+
+	for !h.exhausted && (want <= 0 || len(scores) < want) {
+		next := ResumableQueryNext{}
+		if want > 0 {
+			next.AdditionalK = want - len(scores)
+		}
+
+		var page []VectorScore
+		if page, err = h.next(next); err != nil {
+			return
+		}
+
+		filtered, dropped := filterScores(page, h.minScore, 0)
+		scores = append(scores, filtered...)
+		if dropped || len(page) == 0 || len(page) < next.AdditionalK {
+			h.exhausted = true
+		}
+		if want <= 0 {
+			break
+		}
+	}
+
+	h.consume(len(scores))
+	return
+}
+
+func (h *ResumableQueryHandle) next(n ResumableQueryNext) (scores []VectorScore, err error) {
 	nn := resumableQueryNext{
 		ResumableQueryNext: n,
 		UUID:               h.uuid,
@@ -23,10 +71,36 @@ func (h *ResumableQueryHandle) Next(n ResumableQueryNext) (scores []VectorScore,
 		return
 	}
 
-	scores, err = parseResponse[[]VectorScore](data)
+	scores, err = parseVectorScores(data)
 	return
 }
 
+// first applies the minimum score and maximum results to the first page of the query.
+func (h *ResumableQueryHandle) first(scores []VectorScore, maxResults int) []VectorScore {
+	h.remaining = -1
+	if maxResults > 0 {
+		h.remaining = maxResults
+	}
+
+	filtered, dropped := filterScores(scores, h.minScore, maxResults)
+	h.exhausted = dropped
+	h.consume(len(filtered))
+	return filtered
+}
+
+// consume marks the given number of vectors as returned, and
+// stops fetching the next pages when the maximum results is reached.
+func (h *ResumableQueryHandle) consume(n int) {
+	if h.remaining < 0 {
+		return
+	}
+	h.remaining -= n
+	if h.remaining <= 0 {
+		h.remaining = 0
+		h.exhausted = true
+	}
+}
+
 // Close stops the resumable query and releases the acquired resources.
 func (h *ResumableQueryHandle) Close() (err error) {
 	e := resumableQueryEnd{UUID: h.uuid}
@@ -49,20 +123,24 @@ func (ix *Index) ResumableQuery(q ResumableQuery) (scores []VectorScore, handle
 }
 
 func (ix *Index) resumableQueryInternal(q ResumableQuery, ns string) (scores []VectorScore, handle *ResumableQueryHandle, err error) {
+	if err = ix.validateQuery(q.Vector, q.SparseVector); err != nil {
+		return
+	}
 	data, err := ix.sendJson(buildPath(resumableQueryPath, ns), q)
 	if err != nil {
 		return
 	}
 
-	start, err := parseResponse[resumableQueryStart](data)
+	start, err := parseResumableQueryStart(data)
 	if err != nil {
 		return
 	}
 
-	scores = start.Scores
 	handle = &ResumableQueryHandle{
-		index: ix,
-		uuid:  start.UUID,
+		index:    ix,
+		uuid:     start.UUID,
+		minScore: q.MinScore,
 	}
+	scores = handle.first(start.Scores, q.MaxResults)
 	return
 }
@@ -17,15 +17,16 @@ func (ix *Index) resumableQueryDataInternal(q ResumableQueryData, ns string) (sc
 		return
 	}
 
-	start, err := parseResponse[resumableQueryStart](data)
+	start, err := parseResumableQueryStart(data)
 	if err != nil {
 		return
 	}
 
-	scores = start.Scores
 	handle = &ResumableQueryHandle{
-		index: ix,
-		uuid:  start.UUID,
+		index:    ix,
+		uuid:     start.UUID,
+		minScore: q.MinScore,
 	}
+	scores = handle.first(start.Scores, q.MaxResults)
 	return
 }
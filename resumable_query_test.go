@@ -149,3 +149,76 @@ func TestResumableQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestResumableQueryMinScore(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			client, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			err = client.UpsertMany([]Upsert{
+				{Id: "id0", Vector: []float32{1, 0}},
+				{Id: "id1", Vector: []float32{0.95, 0.05}},
+				{Id: "id2", Vector: []float32{0.9, 0.1}},
+				{Id: "id3", Vector: []float32{0, 1}},
+				{Id: "id4", Vector: []float32{0.01, 1}},
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			minScore := float32(0.9)
+
+			t.Run("min score", func(t *testing.T) {
+				scores, handle, err := client.ResumableQuery(ResumableQuery{
+					Vector:   []float32{1, 0},
+					TopK:     2,
+					MinScore: &minScore,
+				})
+				t.Cleanup(func() {
+					if handle != nil {
+						handle.Close()
+					}
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, len(scores))
+
+				scores, err = handle.Next(ResumableQueryNext{AdditionalK: 3})
+				require.NoError(t, err)
+				require.Equal(t, 1, len(scores))
+				require.Equal(t, "id2", scores[0].Id)
+
+				scores, err = handle.Next(ResumableQueryNext{AdditionalK: 3})
+				require.NoError(t, err)
+				require.Empty(t, scores)
+			})
+
+			t.Run("max results", func(t *testing.T) {
+				scores, handle, err := client.ResumableQuery(ResumableQuery{
+					Vector:     []float32{1, 0},
+					TopK:       2,
+					MaxResults: 3,
+				})
+				t.Cleanup(func() {
+					if handle != nil {
+						handle.Close()
+					}
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, len(scores))
+
+				scores, err = handle.Next(ResumableQueryNext{AdditionalK: 5})
+				require.NoError(t, err)
+				require.Equal(t, 1, len(scores))
+
+				scores, err = handle.Next(ResumableQueryNext{AdditionalK: 5})
+				require.NoError(t, err)
+				require.Empty(t, scores)
+			})
+		})
+	}
+}
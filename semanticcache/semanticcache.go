@@ -0,0 +1,177 @@
+// Package semanticcache implements a semantic cache for LLM responses
+// on top of Upstash Vector indexes with embedding models.
+//
+// Prompts are stored as the data of the vectors and embedded on the
+// server, while the answers are stored in the metadata. A cached answer
+// is returned for prompts that are similar enough to a cached prompt,
+// rather than only for the exact same prompts.
+package semanticcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/upstash/vector-go"
+)
+
+const (
+	// DefaultMinSimilarity is the minimum similarity score used when
+	// no other value is provided.
+	DefaultMinSimilarity = 0.9
+
+	answerKey    = "answer"
+	expiresAtKey = "expiresAt"
+
+	evictBatchSize = 1000
+)
+
+// Options configures the behaviour of a Cache.
+type Options struct {
+	// Minimum similarity score of a cached prompt to the queried prompt
+	// for its answer to be returned. It should be between 0 and 1.
+	// If not provided, defaults to DefaultMinSimilarity.
+	MinSimilarity float32
+
+	// How long the cached answers are valid. The expiry time is stored
+	// in the metadata of the entries, and expired entries are not returned
+	// while they are waiting to be evicted with EvictExpired.
+	// If not provided, the entries do not expire.
+	TTL time.Duration
+
+	// Prefix of the namespace names of the per-user caches.
+	// If not provided, user names are used as the namespace names as they are.
+	NamespacePrefix string
+}
+
+// Cache is a semantic cache stored in a namespace of an index.
+type Cache struct {
+	index     *vector.Index
+	namespace *vector.Namespace
+	options   Options
+}
+
+// New returns a cache stored in the default namespace of the given index.
+// The index must be created with an embedding model.
+func New(index *vector.Index, options Options) *Cache {
+	if options.MinSimilarity == 0 {
+		options.MinSimilarity = DefaultMinSimilarity
+	}
+	return &Cache{
+		index:     index,
+		namespace: index.Namespace(""),
+		options:   options,
+	}
+}
+
+// ForUser returns a cache for the given user, which is stored in a
+// separate namespace, so that the answers are not shared between users.
+func (c *Cache) ForUser(user string) *Cache {
+	return &Cache{
+		index:     c.index,
+		namespace: c.index.Namespace(c.options.NamespacePrefix + user),
+		options:   c.options,
+	}
+}
+
+// Get returns the answer of the most similar cached prompt, and reports
+// whether such a prompt with at least the minimum similarity is found.
+func (c *Cache) Get(prompt string) (answer string, ok bool, err error) {
+	now := time.Now().Unix()
+	scores, err := c.namespace.QueryData(vector.QueryData{
+		Data:            prompt,
+		TopK:            1,
+		IncludeMetadata: true,
+		Filter:          fmt.Sprintf("%s = 0 OR %s > %d", expiresAtKey, expiresAtKey, now),
+	})
+	if err != nil || len(scores) == 0 {
+		return
+	}
+
+	best := scores[0]
+	if best.Score < c.options.MinSimilarity || isExpired(best.Metadata, now) {
+		return
+	}
+
+	answer, ok = best.Metadata[answerKey].(string)
+	return
+}
+
+// Set caches the answer for the given prompt. The answer of an
+// already cached prompt is overwritten.
+func (c *Cache) Set(prompt string, answer string) error {
+	var expiresAt int64
+	if c.options.TTL > 0 {
+		expiresAt = time.Now().Add(c.options.TTL).Unix()
+	}
+
+	return c.namespace.UpsertData(vector.UpsertData{
+		Id:   promptId(prompt),
+		Data: prompt,
+		Metadata: map[string]any{
+			answerKey:    answer,
+			expiresAtKey: expiresAt,
+		},
+	})
+}
+
+// Delete deletes the cached answer for the exact given prompt, and
+// reports whether it was cached.
+func (c *Cache) Delete(prompt string) (ok bool, err error) {
+	return c.namespace.Delete(promptId(prompt))
+}
+
+// Flush deletes all the cached answers.
+func (c *Cache) Flush() error {
+	return c.namespace.Reset()
+}
+
+// EvictExpired deletes the expired entries and returns their count.
+func (c *Cache) EvictExpired() (count int, err error) {
+	now := time.Now().Unix()
+
+	var expired []string
+	cursor := "0"
+	for cursor != "" {
+		var page vector.RangeVectors
+		page, err = c.namespace.Range(vector.Range{
+			Cursor:          cursor,
+			Limit:           evictBatchSize,
+			IncludeMetadata: true,
+		})
+		if err != nil {
+			return
+		}
+
+		for _, v := range page.Vectors {
+			if isExpired(v.Metadata, now) {
+				expired = append(expired, v.Id)
+			}
+		}
+		cursor = page.NextCursor
+	}
+
+	for start := 0; start < len(expired); start += evictBatchSize {
+		end := min(start+evictBatchSize, len(expired))
+
+		var deleted int
+		if deleted, err = c.namespace.DeleteMany(expired[start:end]); err != nil {
+			return
+		}
+		count += deleted
+	}
+	return
+}
+
+// isExpired reports whether the entry with the given metadata is
+// expired at the given unix time.
+func isExpired(metadata map[string]any, now int64) bool {
+	expiresAt, _ := metadata[expiresAtKey].(float64)
+	return expiresAt != 0 && int64(expiresAt) <= now
+}
+
+func promptId(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
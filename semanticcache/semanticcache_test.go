@@ -0,0 +1,99 @@
+package semanticcache
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go"
+)
+
+func init() {
+	err := godotenv.Load("../.env")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		panic(err)
+	}
+}
+
+func newTestCache(t *testing.T, options Options) *Cache {
+	index := vector.NewIndex(
+		os.Getenv("EMBEDDING_"+vector.UrlEnvProperty),
+		os.Getenv("EMBEDDING_"+vector.TokenEnvProperty),
+	)
+	cache := New(index, options)
+	require.NoError(t, cache.Flush())
+	return cache
+}
+
+func waitIndexed(t *testing.T, cache *Cache) {
+	require.Eventually(t, func() bool {
+		info, err := cache.index.Info()
+		require.NoError(t, err)
+		return info.PendingVectorCount == 0
+	}, 10*time.Second, 1*time.Second)
+}
+
+func TestCache(t *testing.T) {
+	cache := newTestCache(t, Options{})
+
+	err := cache.Set("What is the capital of Japan?", "Tokyo")
+	require.NoError(t, err)
+	waitIndexed(t, cache)
+
+	t.Run("similar prompt", func(t *testing.T) {
+		answer, ok, err := cache.Get("What's the capital of Japan?")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "Tokyo", answer)
+	})
+
+	t.Run("different prompt", func(t *testing.T) {
+		_, ok, err := cache.Get("How do I bake bread?")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("per user", func(t *testing.T) {
+		user := cache.ForUser("user")
+		require.NoError(t, user.Flush())
+
+		_, ok, err := user.Get("What is the capital of Japan?")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		ok, err := cache.Delete("What is the capital of Japan?")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := newTestCache(t, Options{TTL: time.Second})
+
+	err := cache.Set("What is the capital of France?", "Paris")
+	require.NoError(t, err)
+	waitIndexed(t, cache)
+
+	time.Sleep(2 * time.Second)
+
+	_, ok, err := cache.Get("What is the capital of France?")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	count, err := cache.EvictExpired()
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestIsExpired(t *testing.T) {
+	require.False(t, isExpired(map[string]any{expiresAtKey: float64(0)}, 100))
+	require.False(t, isExpired(map[string]any{expiresAtKey: float64(101)}, 100))
+	require.True(t, isExpired(map[string]any{expiresAtKey: float64(100)}, 100))
+	require.False(t, isExpired(nil, 100))
+}
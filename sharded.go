@@ -0,0 +1,623 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// virtualNodesPerShard is the number of points each shard
+// has on the consistent hashing ring.
+const virtualNodesPerShard = 128
+
+// ShardedIndex is a client that spreads the vectors over multiple
+// Upstash Vector indexes.
+//
+// Operations on individual vectors are routed to a single shard
+// by consistent hashing on the vector id, while queries are run on all
+// the shards and the results are merged.
+//
+// All the shards are expected to have the same dimension and similarity
+// function. The order of the shards must be kept the same between
+// different clients so that the vectors are routed to the same shards.
+// Appending a new shard to the end of the list moves only a small
+// portion of the ids to the new shard.
+type ShardedIndex struct {
+	shards []*Index
+	ring   hashRing
+}
+
+// NewShardedIndex returns a client that spreads the vectors over the
+// given index clients.
+func NewShardedIndex(shards ...*Index) *ShardedIndex {
+	if len(shards) == 0 {
+		panic("Missing Upstash Vector shards")
+	}
+	return &ShardedIndex{
+		shards: shards,
+		ring:   newHashRing(len(shards)),
+	}
+}
+
+// Shards returns the index clients of the shards.
+func (s *ShardedIndex) Shards() []*Index {
+	return s.shards
+}
+
+// ShardOf returns the position of the shard that the vector
+// with the given id is routed to.
+func (s *ShardedIndex) ShardOf(id string) int {
+	return s.ring.get(id)
+}
+
+// ShardedNamespace is a client for a namespace of a sharded index.
+type ShardedNamespace struct {
+	index *ShardedIndex
+	ns    string
+}
+
+// Namespace returns a new client associated with the given namespace.
+func (s *ShardedIndex) Namespace(namespace string) *ShardedNamespace {
+	return &ShardedNamespace{
+		index: s,
+		ns:    namespace,
+	}
+}
+
+// Upsert updates or inserts a vector to the default namespace of the shard it belongs to.
+// Additional metadata can also be provided while upserting the vector.
+func (s *ShardedIndex) Upsert(u Upsert) (err error) {
+	return s.upsertManyInternal([]Upsert{u}, defaultNamespace)
+}
+
+// UpsertMany updates or inserts some vectors to the default namespace of the shards they belong to.
+// Additional metadata can also be provided for each vector.
+func (s *ShardedIndex) UpsertMany(u []Upsert) (err error) {
+	return s.upsertManyInternal(u, defaultNamespace)
+}
+
+// UpsertData updates or inserts a vector to the default namespace of the shard it belongs to
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided while upserting the vector.
+func (s *ShardedIndex) UpsertData(u UpsertData) (err error) {
+	return s.upsertDataManyInternal([]UpsertData{u}, defaultNamespace)
+}
+
+// UpsertDataMany updates or inserts some vectors to the default namespace of the shards they belong to
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided for each vector.
+func (s *ShardedIndex) UpsertDataMany(u []UpsertData) (err error) {
+	return s.upsertDataManyInternal(u, defaultNamespace)
+}
+
+// Fetch fetches one or more vectors in the default namespace with the ids passed into f,
+// from the shards they belong to.
+// If IncludeVectors is set to true, the vector values are also returned.
+// If IncludeMetadata is set to true, any associated metadata of the vectors is also returned, if any.
+func (s *ShardedIndex) Fetch(f Fetch) (vectors []Vector, err error) {
+	return s.fetchInternal(f, defaultNamespace)
+}
+
+// Query returns the merged result of the query for the given vector in the default
+// namespace of all the shards.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score,
+// which correlates with the similarity of the vectors to the given query vector.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (s *ShardedIndex) Query(q Query) (scores []VectorScore, err error) {
+	return s.queryInternal(q, defaultNamespace)
+}
+
+// QueryData returns the merged result of the query for the given data in the default
+// namespace of all the shards, by converting it to an embedding on the server.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score,
+// which correlates with the similarity of the vectors to the given query vector.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (s *ShardedIndex) QueryData(q QueryData) (scores []VectorScore, err error) {
+	return s.queryDataInternal(q, defaultNamespace)
+}
+
+// QueryNamespaces runs the query for the given vector in the given namespaces of all the
+// shards, or in all the namespaces if none is given, and merges the results.
+// See Index.QueryNamespaces for details.
+func (s *ShardedIndex) QueryNamespaces(q Query, namespaces ...string) (scores []VectorScore, err error) {
+	return s.fanOutScores(resultLimit(q.TopK, q.MaxResults), func(shard *Index) ([]VectorScore, error) {
+		return shard.QueryNamespaces(q, namespaces...)
+	})
+}
+
+// QueryDataNamespaces runs the query for the given data in the given namespaces of all the
+// shards, or in all the namespaces if none is given, and merges the results.
+// See Index.QueryDataNamespaces for details.
+func (s *ShardedIndex) QueryDataNamespaces(q QueryData, namespaces ...string) (scores []VectorScore, err error) {
+	return s.fanOutScores(resultLimit(q.TopK, q.MaxResults), func(shard *Index) ([]VectorScore, error) {
+		return shard.QueryDataNamespaces(q, namespaces...)
+	})
+}
+
+// ResumableQuery starts a resumable query in the default namespace of all the shards
+// and returns the first page of the merged result of the query for the given vector.
+// Then, next pages of the query results can be fetched over the returned handle.
+// After all the needed pages of the results are fetched, it is recommended
+// to close to handle to release the acquired resources.
+func (s *ShardedIndex) ResumableQuery(q ResumableQuery) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return s.resumableQueryInternal(q, defaultNamespace)
+}
+
+// ResumableQueryData starts a resumable query in the default namespace of all the shards
+// and returns the first page of the merged result of the query for the given text data.
+// Then, next pages of the query results can be fetched over the returned handle.
+// After all the needed pages of the results are fetched, it is recommended
+// to close to handle to release the acquired resources.
+func (s *ShardedIndex) ResumableQueryData(q ResumableQueryData) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return s.resumableQueryDataInternal(q, defaultNamespace)
+}
+
+// Range returns a range of vectors in the default namespace, starting with r.Cursor (inclusive),
+// until the end of the vectors in all the shards or until the given r.Limit.
+// The shards are scanned one after another. The initial cursor should be set to "0",
+// and subsequent calls to Range might use the next cursor returned in the response.
+// When r.IncludeVectors is true, values of the vectors are also returned.
+// When r.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (s *ShardedIndex) Range(r Range) (vectors RangeVectors, err error) {
+	return s.rangeInternal(r, defaultNamespace)
+}
+
+// Delete deletes the vector with the given id in the default namespace of the shard it belongs to
+// and reports whether the vector is deleted.
+// If a vector with the given id is not found, Delete returns false.
+func (s *ShardedIndex) Delete(id string) (ok bool, err error) {
+	return s.shards[s.ShardOf(id)].deleteInternal(id, defaultNamespace)
+}
+
+// DeleteMany deletes the vectors with the given ids in the default namespace of the shards they
+// belong to and reports how many of them are deleted.
+func (s *ShardedIndex) DeleteMany(ids []string) (count int, err error) {
+	return s.deleteManyInternal(ids, defaultNamespace)
+}
+
+// Reset deletes all the vectors in the default namespace of all the shards
+// and resets them to initial state.
+func (s *ShardedIndex) Reset() (err error) {
+	return s.resetInternal(defaultNamespace)
+}
+
+// Update updates a vector value, data, or metadata for the given id
+// for the default namespace of the shard it belongs to and reports whether the vector is updated.
+// If a vector with the given id is not found, Update returns false.
+func (s *ShardedIndex) Update(u Update) (ok bool, err error) {
+	return s.shards[s.ShardOf(u.Id)].updateInternal(u, defaultNamespace)
+}
+
+// Info returns the aggregated information about the shards.
+// The vector counts and sizes are summed over the shards, while
+// the dimension and similarity function are taken from the first shard.
+func (s *ShardedIndex) Info() (info IndexInfo, err error) {
+	infos := make([]IndexInfo, len(s.shards))
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		infos[i], err = shard.Info()
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	info.Dimension = infos[0].Dimension
+	info.SimilarityFunction = infos[0].SimilarityFunction
+	info.Namespaces = map[string]NamespaceInfo{}
+	for _, shardInfo := range infos {
+		info.VectorCount += shardInfo.VectorCount
+		info.PendingVectorCount += shardInfo.PendingVectorCount
+		info.IndexSize += shardInfo.IndexSize
+		for name, nsInfo := range shardInfo.Namespaces {
+			total := info.Namespaces[name]
+			total.VectorCount += nsInfo.VectorCount
+			total.PendingVectorCount += nsInfo.PendingVectorCount
+			info.Namespaces[name] = total
+		}
+	}
+	return
+}
+
+// ListNamespaces returns the sorted list of names of namespaces that exist in any of the shards.
+func (s *ShardedIndex) ListNamespaces() (namespaces []string, err error) {
+	lists := make([][]string, len(s.shards))
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		lists[i], err = shard.ListNamespaces()
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	seen := map[string]struct{}{}
+	for _, list := range lists {
+		for _, ns := range list {
+			if _, ok := seen[ns]; ok {
+				continue
+			}
+			seen[ns] = struct{}{}
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return
+}
+
+// DeleteNamespace deletes the namespace in all the shards.
+func (ns *ShardedNamespace) DeleteNamespace() error {
+	return ns.index.forEachShard(func(_ int, shard *Index) error {
+		return shard.Namespace(ns.ns).DeleteNamespace()
+	})
+}
+
+// Upsert updates or inserts a vector to the namespace of the shard it belongs to.
+// Additional metadata can also be provided while upserting the vector.
+func (ns *ShardedNamespace) Upsert(u Upsert) (err error) {
+	return ns.index.upsertManyInternal([]Upsert{u}, ns.ns)
+}
+
+// UpsertMany updates or inserts some vectors to the namespace of the shards they belong to.
+// Additional metadata can also be provided for each vector.
+func (ns *ShardedNamespace) UpsertMany(u []Upsert) (err error) {
+	return ns.index.upsertManyInternal(u, ns.ns)
+}
+
+// UpsertData updates or inserts a vector to the namespace of the shard it belongs to
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided while upserting the vector.
+func (ns *ShardedNamespace) UpsertData(u UpsertData) (err error) {
+	return ns.index.upsertDataManyInternal([]UpsertData{u}, ns.ns)
+}
+
+// UpsertDataMany updates or inserts some vectors to the namespace of the shards they belong to
+// by converting given raw data to an embedding on the server.
+// Additional metadata can also be provided for each vector.
+func (ns *ShardedNamespace) UpsertDataMany(u []UpsertData) (err error) {
+	return ns.index.upsertDataManyInternal(u, ns.ns)
+}
+
+// Fetch fetches one or more vectors in the namespace with the ids passed into f,
+// from the shards they belong to.
+// If IncludeVectors is set to true, the vector values are also returned.
+// If IncludeMetadata is set to true, any associated metadata of the vectors is also returned, if any.
+func (ns *ShardedNamespace) Fetch(f Fetch) (vectors []Vector, err error) {
+	return ns.index.fetchInternal(f, ns.ns)
+}
+
+// Query returns the merged result of the query for the given vector in the
+// namespace of all the shards.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score,
+// which correlates with the similarity of the vectors to the given query vector.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ns *ShardedNamespace) Query(q Query) (scores []VectorScore, err error) {
+	return ns.index.queryInternal(q, ns.ns)
+}
+
+// QueryData returns the merged result of the query for the given data in the
+// namespace of all the shards, by converting it to an embedding on the server.
+// When q.TopK is specified, the result will contain at most q.TopK many vectors.
+// The returned list will contain vectors sorted in descending order of score,
+// which correlates with the similarity of the vectors to the given query vector.
+// When q.IncludeVectors is true, values of the vectors are also returned.
+// When q.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ns *ShardedNamespace) QueryData(q QueryData) (scores []VectorScore, err error) {
+	return ns.index.queryDataInternal(q, ns.ns)
+}
+
+// ResumableQuery starts a resumable query in the namespace of all the shards
+// and returns the first page of the merged result of the query for the given vector.
+// Then, next pages of the query results can be fetched over the returned handle.
+// After all the needed pages of the results are fetched, it is recommended
+// to close to handle to release the acquired resources.
+func (ns *ShardedNamespace) ResumableQuery(q ResumableQuery) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return ns.index.resumableQueryInternal(q, ns.ns)
+}
+
+// ResumableQueryData starts a resumable query in the namespace of all the shards
+// and returns the first page of the merged result of the query for the given text data.
+// Then, next pages of the query results can be fetched over the returned handle.
+// After all the needed pages of the results are fetched, it is recommended
+// to close to handle to release the acquired resources.
+func (ns *ShardedNamespace) ResumableQueryData(q ResumableQueryData) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return ns.index.resumableQueryDataInternal(q, ns.ns)
+}
+
+// Range returns a range of vectors in the namespace, starting with r.Cursor (inclusive),
+// until the end of the vectors in all the shards or until the given r.Limit.
+// The shards are scanned one after another. The initial cursor should be set to "0",
+// and subsequent calls to Range might use the next cursor returned in the response.
+// When r.IncludeVectors is true, values of the vectors are also returned.
+// When r.IncludeMetadata is true, metadata of the vectors are also returned, if any.
+func (ns *ShardedNamespace) Range(r Range) (vectors RangeVectors, err error) {
+	return ns.index.rangeInternal(r, ns.ns)
+}
+
+// Delete deletes the vector with the given id in the namespace of the shard it belongs to
+// and reports whether the vector is deleted.
+// If a vector with the given id is not found, Delete returns false.
+func (ns *ShardedNamespace) Delete(id string) (ok bool, err error) {
+	return ns.index.shards[ns.index.ShardOf(id)].deleteInternal(id, ns.ns)
+}
+
+// DeleteMany deletes the vectors with the given ids in the namespace of the shards they
+// belong to and reports how many of them are deleted.
+func (ns *ShardedNamespace) DeleteMany(ids []string) (count int, err error) {
+	return ns.index.deleteManyInternal(ids, ns.ns)
+}
+
+// Reset deletes all the vectors in the namespace of all the shards
+// and resets them to initial state.
+func (ns *ShardedNamespace) Reset() (err error) {
+	return ns.index.resetInternal(ns.ns)
+}
+
+// Update updates a vector value, data, or metadata for the given id
+// in the namespace of the shard it belongs to and reports whether the vector is updated.
+// If a vector with the given id is not found, Update returns false.
+func (ns *ShardedNamespace) Update(u Update) (ok bool, err error) {
+	return ns.index.shards[ns.index.ShardOf(u.Id)].updateInternal(u, ns.ns)
+}
+
+func (s *ShardedIndex) upsertManyInternal(u []Upsert, ns string) (err error) {
+	groups := make([][]Upsert, len(s.shards))
+	for _, v := range u {
+		i := s.ShardOf(v.Id)
+		groups[i] = append(groups[i], v)
+	}
+	return s.forEachShard(func(i int, shard *Index) error {
+		if len(groups[i]) == 0 {
+			return nil
+		}
+		return shard.upsertManyInternal(groups[i], ns)
+	})
+}
+
+func (s *ShardedIndex) upsertDataManyInternal(u []UpsertData, ns string) (err error) {
+	groups := make([][]UpsertData, len(s.shards))
+	for _, v := range u {
+		i := s.ShardOf(v.Id)
+		groups[i] = append(groups[i], v)
+	}
+	return s.forEachShard(func(i int, shard *Index) error {
+		if len(groups[i]) == 0 {
+			return nil
+		}
+		return shard.upsertDataManyInternal(groups[i], ns)
+	})
+}
+
+func (s *ShardedIndex) fetchInternal(f Fetch, ns string) (vectors []Vector, err error) {
+	ids := make([][]string, len(s.shards))
+	positions := make([][]int, len(s.shards))
+	for pos, id := range f.Ids {
+		i := s.ShardOf(id)
+		ids[i] = append(ids[i], id)
+		positions[i] = append(positions[i], pos)
+	}
+
+	results := make([][]Vector, len(s.shards))
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		if len(ids[i]) == 0 {
+			return nil
+		}
+		sf := f
+		sf.Ids = ids[i]
+		results[i], err = shard.fetchInternal(sf, ns)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// Fetch results are in the same order as the requested ids, so
+	// the results of the shards are put back to the positions of their ids.
+	vectors = make([]Vector, len(f.Ids))
+	for i, res := range results {
+		for j, v := range res {
+			if j < len(positions[i]) {
+				vectors[positions[i][j]] = v
+			}
+		}
+	}
+	return
+}
+
+func (s *ShardedIndex) queryInternal(q Query, ns string) (scores []VectorScore, err error) {
+	return s.fanOutScores(resultLimit(q.TopK, q.MaxResults), func(shard *Index) ([]VectorScore, error) {
+		return shard.queryInternal(q, ns)
+	})
+}
+
+func (s *ShardedIndex) queryDataInternal(q QueryData, ns string) (scores []VectorScore, err error) {
+	return s.fanOutScores(resultLimit(q.TopK, q.MaxResults), func(shard *Index) ([]VectorScore, error) {
+		return shard.queryDataInternal(q, ns)
+	})
+}
+
+func (s *ShardedIndex) deleteManyInternal(ids []string, ns string) (count int, err error) {
+	groups := make([][]string, len(s.shards))
+	for _, id := range ids {
+		i := s.ShardOf(id)
+		groups[i] = append(groups[i], id)
+	}
+
+	counts := make([]int, len(s.shards))
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		if len(groups[i]) == 0 {
+			return nil
+		}
+		counts[i], err = shard.deleteManyInternal(groups[i], ns)
+		return
+	})
+
+	for _, c := range counts {
+		count += c
+	}
+	return
+}
+
+func (s *ShardedIndex) resetInternal(ns string) (err error) {
+	return s.forEachShard(func(_ int, shard *Index) error {
+		return shard.resetInternal(ns)
+	})
+}
+
+func (s *ShardedIndex) rangeInternal(r Range, ns string) (vectors RangeVectors, err error) {
+	shard, cursor, err := parseShardedCursor(r.Cursor, len(s.shards))
+	if err != nil {
+		return
+	}
+
+	for shard < len(s.shards) {
+		sr := r
+		sr.Cursor = cursor
+		if r.Limit > 0 {
+			sr.Limit = r.Limit - len(vectors.Vectors)
+		}
+
+		var res RangeVectors
+		res, err = s.shards[shard].rangeInternal(sr, ns)
+		if err != nil {
+			return
+		}
+
+		vectors.Vectors = append(vectors.Vectors, res.Vectors...)
+		if res.NextCursor == "" {
+			shard, cursor = shard+1, "0"
+		} else {
+			cursor = res.NextCursor
+		}
+
+		if r.Limit <= 0 || len(vectors.Vectors) >= r.Limit {
+			break
+		}
+	}
+
+	if shard < len(s.shards) {
+		vectors.NextCursor = strconv.Itoa(shard) + ":" + cursor
+	}
+	return
+}
+
+// parseShardedCursor parses the cursors in the form of <shard>:<cursor>,
+// where the initial cursor "0" is the beginning of the first shard.
+func parseShardedCursor(cursor string, shards int) (shard int, shardCursor string, err error) {
+	if cursor == "" || cursor == "0" {
+		return 0, "0", nil
+	}
+
+	s, shardCursor, ok := strings.Cut(cursor, ":")
+	if !ok {
+		err = fmt.Errorf("invalid sharded range cursor: %s", cursor)
+		return
+	}
+
+	shard, err = strconv.Atoi(s)
+	if err != nil || shard < 0 || shard >= shards {
+		err = fmt.Errorf("invalid sharded range cursor: %s", cursor)
+	}
+	return
+}
+
+func (s *ShardedIndex) fanOutScores(limit int, query func(shard *Index) ([]VectorScore, error)) (scores []VectorScore, err error) {
+	results := make([][]VectorScore, len(s.shards))
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		results[i], err = query(shard)
+		return
+	})
+	if err != nil {
+		return
+	}
+	scores = mergeScores(results, limit)
+	return
+}
+
+// forEachShard calls fn concurrently for all the shards and
+// returns the errors of the failed calls joined.
+func (s *ShardedIndex) forEachShard(fn func(i int, shard *Index) error) error {
+	errs := make([]error, len(s.shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func(i int, shard *Index) {
+			defer wg.Done()
+			if err := fn(i, shard); err != nil {
+				errs[i] = fmt.Errorf("shard %d: %w", i, err)
+			}
+		}(i, shard)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+type hashRing struct {
+	points []uint64
+	shards []int
+}
+
+func newHashRing(shards int) hashRing {
+	ring := hashRing{
+		points: make([]uint64, 0, shards*virtualNodesPerShard),
+		shards: make([]int, 0, shards*virtualNodesPerShard),
+	}
+
+	type node struct {
+		point uint64
+		shard int
+	}
+	nodes := make([]node, 0, shards*virtualNodesPerShard)
+	for shard := 0; shard < shards; shard++ {
+		for v := 0; v < virtualNodesPerShard; v++ {
+			nodes = append(nodes, node{
+				point: hashKey("shard-" + strconv.Itoa(shard) + "-" + strconv.Itoa(v)),
+				shard: shard,
+			})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].point < nodes[j].point
+	})
+
+	for _, n := range nodes {
+		ring.points = append(ring.points, n.point)
+		ring.shards = append(ring.shards, n.shard)
+	}
+	return ring
+}
+
+func (r hashRing) get(key string) int {
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.shards[i]
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	// fnv alone does not spread the similar keys well enough,
+	// so the hash is finalized with the mixer of splitmix64.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,165 @@
+package vector
+
+import "errors"
+
+// defaultResumableQueryAdditionalK is the page size used by
+// the server when the AdditionalK of the next page is not set.
+const defaultResumableQueryAdditionalK = 10
+
+// ShardedResumableQueryHandle is a handle for the resumable queries
+// started on all the shards of a sharded index.
+//
+// The pages returned from it are merged from the resumable queries
+// of the shards, so that they are in the same order as they would be
+// if all the vectors were in a single index.
+type ShardedResumableQueryHandle struct {
+	handles []*ResumableQueryHandle
+	buffers [][]VectorScore
+	done    []bool
+
+	// remaining is the number of vectors that can still be
+	// returned, or -1 if there is no limit.
+	remaining int
+}
+
+// Next fetches the next page of the query result.
+func (h *ShardedResumableQueryHandle) Next(n ResumableQueryNext) (scores []VectorScore, err error) {
+	if n.AdditionalK <= 0 {
+		n.AdditionalK = defaultResumableQueryAdditionalK
+	}
+	if h.remaining >= 0 && n.AdditionalK > h.remaining {
+		n.AdditionalK = h.remaining
+	}
+
+	// At most n scores can be taken from a single shard for the next page,
+	// so it is enough to have n scores buffered for each shard.
+	errs := make([]error, len(h.handles))
+	done := make(chan struct{}, len(h.handles))
+	pending := 0
+	for i, handle := range h.handles {
+		missing := n.AdditionalK - len(h.buffers[i])
+		if h.done[i] || missing <= 0 {
+			continue
+		}
+
+		pending++
+		go func(i int, handle *ResumableQueryHandle, missing int) {
+			defer func() { done <- struct{}{} }()
+			page, err := handle.Next(ResumableQueryNext{AdditionalK: missing})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			h.buffers[i] = append(h.buffers[i], page...)
+			if len(page) < missing {
+				h.done[i] = true
+			}
+		}(i, handle, missing)
+	}
+	for ; pending > 0; pending-- {
+		<-done
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return
+	}
+
+	scores = h.take(n.AdditionalK)
+	return
+}
+
+// Close stops the resumable queries of all the shards and releases the acquired resources.
+func (h *ShardedResumableQueryHandle) Close() (err error) {
+	errs := make([]error, len(h.handles))
+	for i, handle := range h.handles {
+		errs[i] = handle.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// take removes and returns at most k highest scores from the buffers.
+// It stops early when the buffer of a shard that might have more
+// results is drained, as the next score of that shard is not known yet.
+func (h *ShardedResumableQueryHandle) take(k int) []VectorScore {
+	if h.remaining >= 0 && k > h.remaining {
+		k = h.remaining
+	}
+
+	scores := make([]VectorScore, 0, k)
+	for len(scores) < k {
+		best := -1
+		for i, buffer := range h.buffers {
+			if len(buffer) == 0 {
+				if !h.done[i] {
+					return scores
+				}
+				continue
+			}
+			if best == -1 || buffer[0].Score > h.buffers[best][0].Score {
+				best = i
+			}
+		}
+		if best == -1 {
+			break
+		}
+		scores = append(scores, h.buffers[best][0])
+		h.buffers[best] = h.buffers[best][1:]
+	}
+
+	if h.remaining >= 0 {
+		h.remaining -= len(scores)
+	}
+	return scores
+}
+
+func (s *ShardedIndex) resumableQueryInternal(q ResumableQuery, ns string) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return s.startResumableQuery(resultLimit(q.TopK, q.MaxResults), q.MaxResults, func(shard *Index) ([]VectorScore, *ResumableQueryHandle, error) {
+		return shard.resumableQueryInternal(q, ns)
+	})
+}
+
+func (s *ShardedIndex) resumableQueryDataInternal(q ResumableQueryData, ns string) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	return s.startResumableQuery(resultLimit(q.TopK, q.MaxResults), q.MaxResults, func(shard *Index) ([]VectorScore, *ResumableQueryHandle, error) {
+		return shard.resumableQueryDataInternal(q, ns)
+	})
+}
+
+func (s *ShardedIndex) startResumableQuery(topK int, maxResults int, start func(shard *Index) ([]VectorScore, *ResumableQueryHandle, error)) (scores []VectorScore, handle *ShardedResumableQueryHandle, err error) {
+	h := &ShardedResumableQueryHandle{
+		handles: make([]*ResumableQueryHandle, len(s.shards)),
+		buffers: make([][]VectorScore, len(s.shards)),
+		done:    make([]bool, len(s.shards)),
+	}
+	h.remaining = -1
+	if maxResults > 0 {
+		h.remaining = maxResults
+	}
+
+	err = s.forEachShard(func(i int, shard *Index) (err error) {
+		h.buffers[i], h.handles[i], err = start(shard)
+		if err == nil && topK > 0 && len(h.buffers[i]) < topK {
+			h.done[i] = true
+		}
+		return
+	})
+	if err != nil {
+		// release the queries that are started successfully
+		for _, started := range h.handles {
+			if started != nil {
+				started.Close()
+			}
+		}
+		return
+	}
+
+	if topK <= 0 {
+		// the page size is decided by the server, so take
+		// as many scores as known to be in the correct order.
+		for _, buffer := range h.buffers {
+			topK += len(buffer)
+		}
+	}
+	scores = h.take(topK)
+	handle = h
+	return
+}
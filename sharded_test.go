@@ -0,0 +1,358 @@
+package vector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashRing(t *testing.T) {
+	t.Run("distribution", func(t *testing.T) {
+		ring := newHashRing(4)
+		counts := make([]int, 4)
+		for i := 0; i < 10000; i++ {
+			counts[ring.get("id-"+strconv.Itoa(i))]++
+		}
+		for _, c := range counts {
+			require.InDelta(t, 2500, c, 500)
+		}
+	})
+
+	t.Run("adding shard", func(t *testing.T) {
+		before := newHashRing(4)
+		after := newHashRing(5)
+		moved := 0
+		for i := 0; i < 10000; i++ {
+			id := "id-" + strconv.Itoa(i)
+			b, a := before.get(id), after.get(id)
+			if b != a {
+				require.Equal(t, 4, a)
+				moved++
+			}
+		}
+		require.InDelta(t, 2000, moved, 500)
+	})
+}
+
+func TestParseShardedCursor(t *testing.T) {
+	shard, cursor, err := parseShardedCursor("0", 3)
+	require.NoError(t, err)
+	require.Equal(t, 0, shard)
+	require.Equal(t, "0", cursor)
+
+	shard, cursor, err = parseShardedCursor("2:abc:1", 3)
+	require.NoError(t, err)
+	require.Equal(t, 2, shard)
+	require.Equal(t, "abc:1", cursor)
+
+	_, _, err = parseShardedCursor("3:0", 3)
+	require.Error(t, err)
+
+	_, _, err = parseShardedCursor("abc", 3)
+	require.Error(t, err)
+}
+
+func TestShardedResumableQueryHandleTake(t *testing.T) {
+	h := &ShardedResumableQueryHandle{
+		buffers: [][]VectorScore{
+			{{Id: "a", Score: 0.9}, {Id: "c", Score: 0.5}},
+			{{Id: "b", Score: 0.7}},
+		},
+		done:      []bool{true, false},
+		remaining: -1,
+	}
+
+	scores := h.take(3)
+	require.Equal(t, 2, len(scores))
+	require.Equal(t, "a", scores[0].Id)
+	require.Equal(t, "b", scores[1].Id)
+
+	h.done[1] = true
+	scores = h.take(3)
+	require.Equal(t, 1, len(scores))
+	require.Equal(t, "c", scores[0].Id)
+}
+
+// fakeShard is an in-memory index served over HTTP,
+// so that the routing to the shards can be checked.
+type fakeShard struct {
+	mu      sync.Mutex
+	vectors map[string]map[string]Vector
+
+	// Remaining scores of the resumable queries by their uuids.
+	queries map[string][]VectorScore
+}
+
+func newFakeShard(t *testing.T) (*fakeShard, *Index) {
+	shard := &fakeShard{vectors: map[string]map[string]Vector{}, queries: map[string][]VectorScore{}}
+	server := httptest.NewServer(http.HandlerFunc(shard.serve))
+	t.Cleanup(server.Close)
+	return shard, NewIndex(server.URL, "token")
+}
+
+func (s *fakeShard) ids(ns string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.vectors[ns]))
+	for id := range s.vectors[ns] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *fakeShard) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	operation, ns, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if s.vectors[ns] == nil {
+		s.vectors[ns] = map[string]Vector{}
+	}
+	vectors := s.vectors[ns]
+
+	var result any
+	switch operation {
+	case "upsert":
+		var u []Upsert
+		if json.Unmarshal(body, &u) != nil {
+			u = make([]Upsert, 1)
+			_ = json.Unmarshal(body, &u[0])
+		}
+		for _, v := range u {
+			vectors[v.Id] = Vector{Id: v.Id, Vector: v.Vector, Metadata: v.Metadata}
+		}
+		result = "Success"
+	case "fetch":
+		var f Fetch
+		_ = json.Unmarshal(body, &f)
+		fetched := make([]*Vector, len(f.Ids))
+		for i, id := range f.Ids {
+			if v, ok := vectors[id]; ok {
+				fetched[i] = &v
+			}
+		}
+		result = fetched
+	case "query":
+		var q Query
+		_ = json.Unmarshal(body, &q)
+		scores := s.query(vectors, q.Vector)
+		result = scores[:min(len(scores), q.TopK)]
+	case "resumable-query":
+		var q ResumableQuery
+		_ = json.Unmarshal(body, &q)
+		scores := s.query(vectors, q.Vector)
+		uuid := strconv.Itoa(len(s.queries))
+		k := min(len(scores), q.TopK)
+		s.queries[uuid] = scores[k:]
+		result = resumableQueryStart{UUID: uuid, Scores: scores[:k]}
+	case "resumable-query-next":
+		var n resumableQueryNext
+		_ = json.Unmarshal(body, &n)
+		if n.AdditionalK == 0 {
+			n.AdditionalK = defaultResumableQueryAdditionalK
+		}
+		scores := s.queries[n.UUID]
+		k := min(len(scores), n.AdditionalK)
+		s.queries[n.UUID] = scores[k:]
+		result = scores[:k]
+	case "resumable-query-end":
+		var e resumableQueryEnd
+		_ = json.Unmarshal(body, &e)
+		delete(s.queries, e.UUID)
+		result = "Success"
+	case "range":
+		var rr Range
+		_ = json.Unmarshal(body, &rr)
+		ids := make([]string, 0, len(vectors))
+		for id := range vectors {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		start, _ := strconv.Atoi(rr.Cursor)
+		end := min(len(ids), start+rr.Limit)
+		page := RangeVectors{Vectors: []Vector{}}
+		for _, id := range ids[start:end] {
+			page.Vectors = append(page.Vectors, vectors[id])
+		}
+		if end < len(ids) {
+			page.NextCursor = strconv.Itoa(end)
+		}
+		result = page
+	case "delete":
+		var ids []string
+		if json.Unmarshal(body, &ids) != nil {
+			ids = []string{string(body)}
+		}
+		count := 0
+		for _, id := range ids {
+			if _, ok := vectors[id]; ok {
+				delete(vectors, id)
+				count++
+			}
+		}
+		result = deleted{Deleted: count}
+	case "info":
+		count := 0
+		for _, vectors := range s.vectors {
+			count += len(vectors)
+		}
+		result = IndexInfo{VectorCount: count, Dimension: 2, SimilarityFunction: "DOT_PRODUCT"}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"Not Found","status":404}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(response[any]{Result: result})
+}
+
+// query returns the scores of all the vectors in descending order.
+func (s *fakeShard) query(vectors map[string]Vector, vector []float32) []VectorScore {
+	scores := []VectorScore{}
+	for _, v := range vectors {
+		var score float32
+		for i := range vector {
+			score += vector[i] * v.Vector[i]
+		}
+		scores = append(scores, VectorScore{Id: v.Id, Score: score})
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
+
+func TestShardedIndex(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			fake0, shard0 := newFakeShard(t)
+			fake1, shard1 := newFakeShard(t)
+			index := NewShardedIndex(shard0, shard1)
+			namespace := index.Namespace(ns)
+
+			u := make([]Upsert, 20)
+			ids := make([]string, len(u))
+			for i := range u {
+				ids[i] = randomString()
+				u[i] = Upsert{
+					Id:       ids[i],
+					Vector:   []float32{float32(i + 1), 1},
+					Metadata: map[string]any{"i": i},
+				}
+			}
+
+			err := namespace.UpsertMany(u)
+			require.NoError(t, err)
+
+			t.Run("routing", func(t *testing.T) {
+				var expected0, expected1 []string
+				for _, id := range ids {
+					if index.ShardOf(id) == 0 {
+						expected0 = append(expected0, id)
+					} else {
+						expected1 = append(expected1, id)
+					}
+				}
+				require.NotEmpty(t, expected0)
+				require.NotEmpty(t, expected1)
+				sort.Strings(expected0)
+				sort.Strings(expected1)
+				require.Equal(t, expected0, fake0.ids(ns))
+				require.Equal(t, expected1, fake1.ids(ns))
+
+				info, err := index.Info()
+				require.NoError(t, err)
+				require.Equal(t, len(ids), info.VectorCount)
+			})
+
+			t.Run("fetch", func(t *testing.T) {
+				fetchIds := append([]string{"missing"}, ids...)
+				vectors, err := namespace.Fetch(Fetch{
+					Ids:             fetchIds,
+					IncludeMetadata: true,
+				})
+				require.NoError(t, err)
+				require.Equal(t, len(fetchIds), len(vectors))
+				require.Equal(t, Vector{}, vectors[0])
+				for i, v := range vectors[1:] {
+					require.Equal(t, ids[i], v.Id)
+					require.Equal(t, float64(i), v.Metadata["i"])
+				}
+			})
+
+			t.Run("query", func(t *testing.T) {
+				scores, err := namespace.Query(Query{
+					Vector: []float32{1, 0},
+					TopK:   3,
+				})
+				require.NoError(t, err)
+				require.Equal(t, 3, len(scores))
+				for i, score := range scores {
+					require.Equal(t, ids[len(ids)-1-i], score.Id)
+				}
+			})
+
+			t.Run("resumable query", func(t *testing.T) {
+				scores, handle, err := namespace.ResumableQuery(ResumableQuery{
+					Vector: []float32{1, 0},
+					TopK:   2,
+				})
+				require.NoError(t, err)
+				defer handle.Close()
+				require.Equal(t, 2, len(scores))
+
+				// The page size is the default of the server when AdditionalK is not set.
+				for len(scores) < len(ids) {
+					page, err := handle.Next(ResumableQueryNext{})
+					require.NoError(t, err)
+					require.NotEmpty(t, page)
+					require.LessOrEqual(t, len(page), defaultResumableQueryAdditionalK)
+					scores = append(scores, page...)
+				}
+				require.Equal(t, len(ids), len(scores))
+				for i, score := range scores {
+					require.Equal(t, ids[len(ids)-1-i], score.Id)
+				}
+
+				page, err := handle.Next(ResumableQueryNext{})
+				require.NoError(t, err)
+				require.Empty(t, page)
+			})
+
+			t.Run("range", func(t *testing.T) {
+				var all []string
+				vectors, err := namespace.Range(Range{Cursor: "0", Limit: 3})
+				require.NoError(t, err)
+				for _, v := range vectors.Vectors {
+					all = append(all, v.Id)
+				}
+				for vectors.NextCursor != "" {
+					vectors, err = namespace.Range(Range{Cursor: vectors.NextCursor, Limit: 3})
+					require.NoError(t, err)
+					for _, v := range vectors.Vectors {
+						all = append(all, v.Id)
+					}
+				}
+				require.ElementsMatch(t, ids, all)
+			})
+
+			t.Run("delete many", func(t *testing.T) {
+				count, err := namespace.DeleteMany(ids[:5])
+				require.NoError(t, err)
+				require.Equal(t, 5, count)
+
+				ok, err := namespace.Delete(ids[5])
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, len(ids)-6, len(fake0.ids(ns))+len(fake1.ids(ns)))
+			})
+		})
+	}
+}
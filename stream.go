@@ -0,0 +1,302 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// vectorStream decodes the vectors of a response incrementally
+// as they are read from the response body, without reading the whole
+// body into memory.
+type vectorStream struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+
+	// nested is set when the vectors are in the "vectors" field of
+	// the result object, rather than being the result itself.
+	nested bool
+
+	nextCursor string
+	message    string
+	done       bool
+	closed     bool
+}
+
+// openVectorStream sends the request and positions the
+// stream at the start of the vectors in the response.
+func (ix *Index) openVectorStream(path string, obj any, nested bool) (s *vectorStream, err error) {
+	body, err := ix.openJson(path, obj)
+	if err != nil {
+		return
+	}
+
+	s = &vectorStream{
+		body:   body,
+		dec:    json.NewDecoder(body),
+		nested: nested,
+	}
+	if err = s.start(); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return
+}
+
+// start reads the response until the start of the vectors array. If the
+// response does not contain any vector, it is read until the end.
+func (s *vectorStream) start() error {
+	if err := s.delim('{'); err != nil {
+		return err
+	}
+
+	for s.dec.More() {
+		key, err := s.key()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "result":
+			found, err := s.startResult()
+			if err != nil || found {
+				return err
+			}
+		case "error":
+			if err = s.dec.Decode(&s.message); err != nil {
+				return err
+			}
+		default:
+			if err = s.skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return s.end()
+}
+
+// startResult reads the result until the start of the vectors array,
+// and reports whether the array is found.
+func (s *vectorStream) startResult() (found bool, err error) {
+	if !s.nested {
+		return s.startArray()
+	}
+
+	token, err := s.dec.Token()
+	if err != nil || token == nil {
+		return
+	}
+	if token != json.Delim('{') {
+		return false, fmt.Errorf("unexpected token in response: %v", token)
+	}
+
+	for s.dec.More() {
+		var key string
+		if key, err = s.key(); err != nil {
+			return
+		}
+
+		switch key {
+		case "vectors":
+			if found, err = s.startArray(); err != nil || found {
+				return
+			}
+		case "nextCursor":
+			if err = s.dec.Decode(&s.nextCursor); err != nil {
+				return
+			}
+		default:
+			if err = s.skip(); err != nil {
+				return
+			}
+		}
+	}
+	return false, s.delim('}')
+}
+
+// startArray reads the start of an array, and reports whether it is found.
+// A null value is treated as a missing array.
+func (s *vectorStream) startArray() (found bool, err error) {
+	token, err := s.dec.Token()
+	if err != nil || token == nil {
+		return
+	}
+	if token != json.Delim('[') {
+		return false, fmt.Errorf("unexpected token in response: %v", token)
+	}
+	return true, nil
+}
+
+// next decodes the next vector, and reports whether there is one.
+func (s *vectorStream) next(v *Vector) (ok bool, err error) {
+	if s.done {
+		return false, nil
+	}
+
+	if s.dec.More() {
+		*v = Vector{}
+		if err = s.dec.Decode(v); err != nil {
+			return
+		}
+		return true, nil
+	}
+
+	if err = s.delim(']'); err != nil {
+		return
+	}
+	return false, s.finish()
+}
+
+// finish reads the rest of the response after the vectors array.
+func (s *vectorStream) finish() error {
+	if s.nested {
+		for s.dec.More() {
+			key, err := s.key()
+			if err != nil {
+				return err
+			}
+			if key == "nextCursor" {
+				err = s.dec.Decode(&s.nextCursor)
+			} else {
+				err = s.skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.delim('}'); err != nil {
+			return err
+		}
+	}
+
+	for s.dec.More() {
+		key, err := s.key()
+		if err != nil {
+			return err
+		}
+		if key == "error" {
+			err = s.dec.Decode(&s.message)
+		} else {
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.end()
+}
+
+// end reads the end of the response, and returns the error in the response, if any.
+func (s *vectorStream) end() error {
+	s.done = true
+	if err := s.delim('}'); err != nil {
+		return err
+	}
+	if s.message != "" {
+		return errors.New(s.message)
+	}
+	return nil
+}
+
+func (s *vectorStream) key() (string, error) {
+	token, err := s.dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token in response: %v", token)
+	}
+	return key, nil
+}
+
+func (s *vectorStream) delim(d json.Delim) error {
+	token, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != d {
+		return fmt.Errorf("unexpected token in response: %v", token)
+	}
+	return nil
+}
+
+func (s *vectorStream) skip() error {
+	var raw json.RawMessage
+	return s.dec.Decode(&raw)
+}
+
+func (s *vectorStream) close() error {
+	s.done = true
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.body.Close()
+}
+
+// VectorIterator iterates over the vectors of a response, decoding
+// them as they are read from the response body.
+//
+// The iterator should be closed after it is used, to release the
+// underlying connection.
+//
+//	it := index.FetchIterator(vector.Fetch{Ids: ids, IncludeVectors: true})
+//	defer it.Close()
+//	for it.Next() {
+//		v := it.Vector()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type VectorIterator struct {
+	open   func() (*vectorStream, error)
+	stream *vectorStream
+	vector Vector
+	err    error
+}
+
+// Next advances the iterator to the next vector, which is then
+// available through Vector. It returns false when there are no
+// more vectors or an error occurs, which is reported by Err.
+func (it *VectorIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.stream == nil {
+		if it.open == nil {
+			return false
+		}
+		if it.stream, it.err = it.open(); it.err != nil {
+			return false
+		}
+		it.open = nil
+	}
+
+	var ok bool
+	if ok, it.err = it.stream.next(&it.vector); !ok {
+		it.stream.close()
+	}
+	return ok
+}
+
+// Vector returns the current vector.
+func (it *VectorIterator) Vector() Vector {
+	return it.vector
+}
+
+// Err returns the error that occurred during the iteration, if any.
+func (it *VectorIterator) Err() error {
+	return it.err
+}
+
+// Close closes the iterator.
+func (it *VectorIterator) Close() error {
+	it.open = nil
+	if it.stream != nil {
+		return it.stream.close()
+	}
+	return nil
+}
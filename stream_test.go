@@ -0,0 +1,109 @@
+package vector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorStream(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"result":{"nextCursor":"2","vectors":[{"id":"a","vector":[0.1,0.2]},{"id":"b","metadata":{"foo":"bar"}}]}}`,
+		"2": `{"status":200,"result":{"vectors":[{"id":"c","unknown":[{}]}],"nextCursor":"3"}}`,
+		"3": `{"result":{"nextCursor":"","vectors":[]}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case rangePath + "/ns":
+			var req Range
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, defaultRangeIteratorLimit, req.Limit)
+			_, _ = w.Write([]byte(pages[req.Cursor]))
+		case fetchPath:
+			_, _ = w.Write([]byte(`{"result":[{"id":"a","vector":[1,2]},null,{"id":"c"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"error":"Not found","status":404}`))
+		}
+	}))
+	defer server.Close()
+
+	index := NewIndex(server.URL, "token")
+
+	t.Run("range", func(t *testing.T) {
+		it := index.Namespace("ns").RangeIterator(Range{})
+		defer it.Close()
+
+		var ids []string
+		var cursors []string
+		for it.Next() {
+			ids = append(ids, it.Vector().Id)
+			cursors = append(cursors, it.Cursor())
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{"a", "b", "c"}, ids)
+		require.Equal(t, []string{"0", "0", "2"}, cursors)
+		require.Equal(t, "", it.Cursor())
+		require.False(t, it.Next())
+	})
+
+	t.Run("fetch", func(t *testing.T) {
+		it := index.FetchIterator(Fetch{Ids: []string{"a", "b", "c"}})
+		defer it.Close()
+
+		var vectors []Vector
+		for it.Next() {
+			vectors = append(vectors, it.Vector())
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []Vector{{Id: "a", Vector: []float32{1, 2}}, {}, {Id: "c"}}, vectors)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		it := index.RangeIterator(Range{})
+		defer it.Close()
+
+		require.False(t, it.Next())
+		require.EqualError(t, it.Err(), "Not found")
+	})
+}
+
+func TestRangeIterator(t *testing.T) {
+	for _, ns := range namespaces {
+		t.Run("namespace_"+ns, func(t *testing.T) {
+			client, err := newTestClient(testClientTypeDense, ns)
+			require.NoError(t, err)
+
+			err = client.Reset()
+			require.NoError(t, err)
+
+			upserts := make([]Upsert, 25)
+			for i := range upserts {
+				upserts[i] = Upsert{Id: randomString(), Vector: []float32{0.6, 0.8}}
+			}
+			err = client.UpsertMany(upserts)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				info, err := client.Info()
+				require.NoError(t, err)
+				return info.PendingVectorCount == 0
+			}, 10*time.Second, 1*time.Second)
+
+			it := client.RangeIterator(Range{Limit: 10, IncludeVectors: true})
+			defer it.Close()
+
+			count := 0
+			for it.Next() {
+				require.Equal(t, []float32{0.6, 0.8}, it.Vector().Vector)
+				count++
+			}
+			require.NoError(t, it.Err())
+			require.Equal(t, len(upserts), count)
+		})
+	}
+}
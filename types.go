@@ -164,6 +164,18 @@ type Query struct {
 	// from dense and sparse components of a hybrid index.
 	// If not provided, defaults to RRF.
 	FusionAlgorithm FusionAlgorithm `json:"fusionAlgorithm,omitempty"`
+
+	// Minimum score of the vectors to be returned.
+	// It is applied on the client, and vectors with lower
+	// scores are dropped from the query response.
+	// If not provided, no vectors are dropped.
+	MinScore *float32 `json:"-"`
+
+	// The maximum number of vectors to be returned
+	// after the vectors with lower scores than MinScore
+	// are dropped. It is applied on the client.
+	// If not provided, all the remaining vectors are returned.
+	MaxResults int `json:"-"`
 }
 
 type QueryData struct {
@@ -201,6 +213,18 @@ type QueryData struct {
 	// indexes with Upstash-hosted embedding models.
 	// If not provided, defaults to hybrid query mode.
 	QueryMode QueryMode `json:"queryMode,omitempty"`
+
+	// Minimum score of the vectors to be returned.
+	// It is applied on the client, and vectors with lower
+	// scores are dropped from the query response.
+	// If not provided, no vectors are dropped.
+	MinScore *float32 `json:"-"`
+
+	// The maximum number of vectors to be returned
+	// after the vectors with lower scores than MinScore
+	// are dropped. It is applied on the client.
+	// If not provided, all the remaining vectors are returned.
+	MaxResults int `json:"-"`
 }
 
 type Vector struct {
@@ -239,6 +263,10 @@ type VectorScore struct {
 
 	// Optional data of the vector.
 	Data string `json:"data,omitempty"`
+
+	// Namespace the vector belongs to.
+	// It is only set for the queries run over multiple namespaces.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type Fetch struct {
@@ -305,6 +333,20 @@ type IndexInfo struct {
 	Namespaces map[string]NamespaceInfo `json:"namespaces"`
 }
 
+const (
+	// SimilarityFunctionCosine scores the vectors as
+	// (1 + cosine_similarity) / 2.
+	SimilarityFunctionCosine = "COSINE"
+
+	// SimilarityFunctionEuclidean scores the vectors as
+	// 1 / (1 + squared_euclidean_distance).
+	SimilarityFunctionEuclidean = "EUCLIDEAN"
+
+	// SimilarityFunctionDotProduct scores the vectors as
+	// (1 + dot_product) / 2.
+	SimilarityFunctionDotProduct = "DOT_PRODUCT"
+)
+
 type NamespaceInfo struct {
 	// The number of vectors in the namespace of the index.
 	VectorCount int `json:"vectorCount"`
@@ -396,6 +438,20 @@ type ResumableQuery struct {
 	// Maximum idle time for the resumable query in seconds.
 	// If not provided, defaults to 1 hour.
 	MaxIdle uint32 `json:"maxIdle,omitempty"`
+
+	// Minimum score of the vectors to be returned.
+	// It is applied on the client, and vectors with lower
+	// scores are dropped from the query response. Since the
+	// results are sorted by score, no more pages are fetched
+	// once a vector with a lower score is seen.
+	// If not provided, no vectors are dropped.
+	MinScore *float32 `json:"-"`
+
+	// The maximum number of vectors to be returned in total,
+	// over all the pages, after the vectors with lower scores
+	// than MinScore are dropped. It is applied on the client.
+	// If not provided, all the remaining vectors are returned.
+	MaxResults int `json:"-"`
 }
 
 type ResumableQueryData struct {
@@ -437,6 +493,267 @@ type ResumableQueryData struct {
 	// Maximum idle time for the resumable query in seconds.
 	// If not provided, defaults to 1 hour.
 	MaxIdle uint32 `json:"maxIdle,omitempty"`
+
+	// Minimum score of the vectors to be returned.
+	// It is applied on the client, and vectors with lower
+	// scores are dropped from the query response. Since the
+	// results are sorted by score, no more pages are fetched
+	// once a vector with a lower score is seen.
+	// If not provided, no vectors are dropped.
+	MinScore *float32 `json:"-"`
+
+	// The maximum number of vectors to be returned in total,
+	// over all the pages, after the vectors with lower scores
+	// than MinScore are dropped. It is applied on the client.
+	// If not provided, all the remaining vectors are returned.
+	MaxResults int `json:"-"`
+}
+
+type QueryMMR struct {
+	// The dense query vector for dense and hybrid indexes.
+	Vector []float32
+
+	// The sparse query vector for sparse and hybrid indexes.
+	SparseVector *SparseVector
+
+	// The maximum number of vectors that will
+	// be returned for the query response.
+	// If not provided, defaults to 10.
+	TopK int
+
+	// The number of candidate vectors to fetch from the index,
+	// which are re-ranked to select the returned vectors.
+	// If not provided, defaults to 4 * TopK.
+	FetchK int
+
+	// The trade-off between the relevance and the diversity
+	// of the returned vectors, between 0 and 1. Higher values
+	// favor the relevance and lower values favor the diversity.
+	// If not provided, defaults to 0.5.
+	Lambda *float32
+
+	// Whether to include vector values in the query response.
+	IncludeVectors bool
+
+	// Whether to include metadata in the query response, if any.
+	IncludeMetadata bool
+
+	// Whether to include data in the query response, if any.
+	IncludeData bool
+
+	// Query filter
+	Filter any
+
+	// Weighting strategy to be used for sparse vectors.
+	// If not provided, no weighting will be used.
+	WeightingStrategy WeightingStrategy
+
+	// Fusion algorithm to use while fusing scores
+	// from dense and sparse components of a hybrid index.
+	// If not provided, defaults to RRF.
+	FusionAlgorithm FusionAlgorithm
+}
+
+type QueryById struct {
+	// Id of the vector whose values are used as the query vector.
+	// The vector itself is excluded from the query response.
+	Id string
+
+	// Optional prefix of the ids to exclude from the query response,
+	// such as the ids of the other chunks of the same document.
+	ExcludePrefix string
+
+	// The maximum number of vectors that will
+	// be returned for the query response.
+	// If not provided, defaults to 10.
+	TopK int
+
+	// Whether to include vector values in the query response.
+	IncludeVectors bool
+
+	// Whether to include metadata in the query response, if any.
+	IncludeMetadata bool
+
+	// Whether to include data in the query response, if any.
+	IncludeData bool
+
+	// Query filter
+	Filter any
+
+	// Weighting strategy to be used for sparse vectors.
+	// If not provided, no weighting will be used.
+	WeightingStrategy WeightingStrategy
+
+	// Fusion algorithm to use while fusing scores
+	// from dense and sparse components of a hybrid index.
+	// If not provided, defaults to RRF.
+	FusionAlgorithm FusionAlgorithm
+}
+
+type RecommendExample struct {
+	// Id of the example vector. Vectors with the ids of the
+	// examples are excluded from the recommendations.
+	// When both Vector and SparseVector are empty, values of
+	// the vector are fetched from the index.
+	Id string
+
+	// Optional dense vector values of the example.
+	Vector []float32
+
+	// Optional sparse vector values of the example.
+	SparseVector *SparseVector
+}
+
+type Recommend struct {
+	// Examples that the recommended vectors should be similar to.
+	// At least one positive example is required.
+	Positive []RecommendExample
+
+	// Examples that the recommended vectors should not be similar to.
+	Negative []RecommendExample
+
+	// Weight of the average of the positive examples in the query vector.
+	// If not provided, defaults to 1.
+	PositiveWeight float32
+
+	// Weight of the average of the negative examples, which is
+	// subtracted from the query vector.
+	// If not provided, defaults to 0.5.
+	NegativeWeight *float32
+
+	// The maximum number of vectors that will
+	// be returned for the query response.
+	// If not provided, defaults to 10.
+	TopK int
+
+	// Whether to include vector values in the query response.
+	IncludeVectors bool
+
+	// Whether to include metadata in the query response, if any.
+	IncludeMetadata bool
+
+	// Whether to include data in the query response, if any.
+	IncludeData bool
+
+	// Query filter
+	Filter any
+
+	// Weighting strategy to be used for sparse vectors.
+	// If not provided, no weighting will be used.
+	WeightingStrategy WeightingStrategy
+
+	// Fusion algorithm to use while fusing scores
+	// from dense and sparse components of a hybrid index.
+	// If not provided, defaults to RRF.
+	FusionAlgorithm FusionAlgorithm
+}
+
+type QueryGroups struct {
+	// The dense query vector for dense and hybrid indexes.
+	Vector []float32
+
+	// The sparse query vector for sparse and hybrid indexes.
+	SparseVector *SparseVector
+
+	// Metadata key to group the vectors by, such as "doc_id".
+	// Vectors without the key in their metadata are skipped.
+	GroupBy string
+
+	// The maximum number of groups that will
+	// be returned for the query response.
+	// If not provided, defaults to 10.
+	Groups int
+
+	// The maximum number of vectors in a group.
+	// If not provided, defaults to 1.
+	GroupSize int
+
+	// The number of vectors fetched with each page of the
+	// underlying resumable query.
+	// If not provided, defaults to Groups * GroupSize.
+	PageSize int
+
+	// The maximum number of vectors to scan while filling the groups.
+	// If not provided, defaults to 10 * Groups * GroupSize.
+	MaxCandidates int
+
+	// Whether to include vector values in the query response.
+	IncludeVectors bool
+
+	// Whether to include data in the query response, if any.
+	IncludeData bool
+
+	// Query filter
+	Filter any
+
+	// Weighting strategy to be used for sparse vectors.
+	// If not provided, no weighting will be used.
+	WeightingStrategy WeightingStrategy
+
+	// Fusion algorithm to use while fusing scores
+	// from dense and sparse components of a hybrid index.
+	// If not provided, defaults to RRF.
+	FusionAlgorithm FusionAlgorithm
+}
+
+type QueryDataGroups struct {
+	// Raw data.
+	// Data will be converted to the vector embedding on the server.
+	Data string
+
+	// Metadata key to group the vectors by, such as "doc_id".
+	// Vectors without the key in their metadata are skipped.
+	GroupBy string
+
+	// The maximum number of groups that will
+	// be returned for the query response.
+	// If not provided, defaults to 10.
+	Groups int
+
+	// The maximum number of vectors in a group.
+	// If not provided, defaults to 1.
+	GroupSize int
+
+	// The number of vectors fetched with each page of the
+	// underlying resumable query.
+	// If not provided, defaults to Groups * GroupSize.
+	PageSize int
+
+	// The maximum number of vectors to scan while filling the groups.
+	// If not provided, defaults to 10 * Groups * GroupSize.
+	MaxCandidates int
+
+	// Whether to include vector values in the query response.
+	IncludeVectors bool
+
+	// Whether to include data in the query response, if any.
+	IncludeData bool
+
+	// Query filter
+	Filter any
+
+	// Weighting strategy to be used for sparse vectors.
+	// If not provided, no weighting will be used.
+	WeightingStrategy WeightingStrategy
+
+	// Fusion algorithm to use while fusing scores
+	// from dense and sparse components of a hybrid index.
+	// If not provided, defaults to RRF.
+	FusionAlgorithm FusionAlgorithm
+
+	// Specifies whether to run the query in only the
+	// dense index, only the sparse index, or in both for hybrid
+	// indexes with Upstash-hosted embedding models.
+	// If not provided, defaults to hybrid query mode.
+	QueryMode QueryMode
+}
+
+type ScoreGroup struct {
+	// Value of the metadata key the vectors are grouped by.
+	Value any
+
+	// Vectors in the group, sorted in descending order of score.
+	Scores []VectorScore
 }
 
 type ResumableQueryNext struct {
@@ -10,6 +10,14 @@ func (ix *Index) Update(u Update) (ok bool, err error) {
 }
 
 func (ix *Index) updateInternal(u Update, ns string) (ok bool, err error) {
+	if err = ix.checkWritable("update"); err != nil {
+		return
+	}
+	if err = ix.validateUpdate(u); err != nil {
+		return
+	}
+	defer ix.invalidateQueryCache(ns)
+
 	data, err := ix.sendJson(buildPath(updatePath, ns), u)
 	if err != nil {
 		return
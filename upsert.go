@@ -15,7 +15,15 @@ func (ix *Index) UpsertMany(u []Upsert) (err error) {
 }
 
 func (ix *Index) upsertInternal(u Upsert, ns string) (err error) {
-	data, err := ix.sendJson(buildPath(upsertPath, ns), u)
+	if err = ix.checkWritable("upsert"); err != nil {
+		return
+	}
+	if err = ix.validateUpserts(u); err != nil {
+		return
+	}
+	defer ix.invalidateQueryCache(ns)
+
+	data, err := ix.sendUpserts(buildPath(upsertPath, ns), []Upsert{u}, true)
 	if err != nil {
 		return
 	}
@@ -24,7 +32,15 @@ func (ix *Index) upsertInternal(u Upsert, ns string) (err error) {
 }
 
 func (ix *Index) upsertManyInternal(u []Upsert, ns string) (err error) {
-	data, err := ix.sendJson(buildPath(upsertPath, ns), u)
+	if err = ix.checkWritable("upsert"); err != nil {
+		return
+	}
+	if err = ix.validateUpserts(u...); err != nil {
+		return
+	}
+	defer ix.invalidateQueryCache(ns)
+
+	data, err := ix.sendUpserts(buildPath(upsertPath, ns), u, false)
 	if err != nil {
 		return
 	}
@@ -17,6 +17,8 @@ func (ix *Index) UpsertDataMany(u []UpsertData) (err error) {
 }
 
 func (ix *Index) upsertDataInternal(u UpsertData, ns string) (err error) {
+	defer ix.invalidateQueryCache(ns)
+
 	data, err := ix.sendJson(buildPath(upsertDataPath, ns), u)
 	if err != nil {
 		return
@@ -26,6 +28,8 @@ func (ix *Index) upsertDataInternal(u UpsertData, ns string) (err error) {
 }
 
 func (ix *Index) upsertDataManyInternal(u []UpsertData, ns string) (err error) {
+	defer ix.invalidateQueryCache(ns)
+
 	data, err := ix.sendJson(buildPath(upsertDataPath, ns), u)
 	if err != nil {
 		return
@@ -0,0 +1,158 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ValidationError is returned when some of the vectors in a request
+// fail the client-side validation. The request is not sent in that case.
+type ValidationError struct {
+	// The invalid vectors, in the order they appear in the request.
+	Vectors []InvalidVector
+}
+
+// InvalidVector describes a vector that failed the client-side validation.
+type InvalidVector struct {
+	// Position of the vector in the request.
+	Index int
+
+	// Id of the vector, or empty for query vectors.
+	Id string
+
+	// Reason of the failure.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "validation failed for %d vector(s):", len(e.Vectors))
+	for _, v := range e.Vectors {
+		if v.Id != "" {
+			fmt.Fprintf(&b, " %q: %v;", v.Id, v.Err)
+		} else {
+			fmt.Fprintf(&b, " #%d: %v;", v.Index, v.Err)
+		}
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Vectors))
+	for i, v := range e.Vectors {
+		errs[i] = v.Err
+	}
+	return errs
+}
+
+// Ids returns the ids of the invalid vectors.
+func (e *ValidationError) Ids() []string {
+	ids := make([]string, 0, len(e.Vectors))
+	for _, v := range e.Vectors {
+		if v.Id != "" {
+			ids = append(ids, v.Id)
+		}
+	}
+	return ids
+}
+
+type validatedVector struct {
+	id           string
+	requireId    bool
+	vector       []float32
+	sparseVector *SparseVector
+}
+
+func (ix *Index) validateUpserts(u ...Upsert) error {
+	if !ix.validate {
+		return nil
+	}
+	vectors := make([]validatedVector, len(u))
+	for i := range u {
+		vectors[i] = validatedVector{id: u[i].Id, requireId: true, vector: u[i].Vector, sparseVector: u[i].SparseVector}
+	}
+	return ix.validateVectors(vectors)
+}
+
+func (ix *Index) validateUpdate(u Update) error {
+	if !ix.validate {
+		return nil
+	}
+	return ix.validateVectors([]validatedVector{{id: u.Id, requireId: true, vector: u.Vector, sparseVector: u.SparseVector}})
+}
+
+func (ix *Index) validateQuery(vector []float32, sparseVector *SparseVector) error {
+	if !ix.validate {
+		return nil
+	}
+	return ix.validateVectors([]validatedVector{{vector: vector, sparseVector: sparseVector}})
+}
+
+// validateVectors validates the vectors against the dimension and the similarity
+// function of the index. The index information is only fetched when there
+// are dense vectors to validate.
+func (ix *Index) validateVectors(vectors []validatedVector) error {
+	var info IndexInfo
+	for _, v := range vectors {
+		if v.vector != nil {
+			var err error
+			if info, err = ix.cachedInfo(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	var invalid []InvalidVector
+	for i, v := range vectors {
+		if err := validateVector(info, v); err != nil {
+			invalid = append(invalid, InvalidVector{Index: i, Id: v.id, Err: err})
+		}
+	}
+
+	if invalid != nil {
+		return &ValidationError{Vectors: invalid}
+	}
+	return nil
+}
+
+func validateVector(info IndexInfo, v validatedVector) error {
+	if v.requireId && v.id == "" {
+		return fmt.Errorf("id is empty")
+	}
+
+	if v.vector != nil {
+		if info.Dimension > 0 && len(v.vector) != info.Dimension {
+			return fmt.Errorf("vector dimension %d does not match the index dimension %d", len(v.vector), info.Dimension)
+		}
+		zero := true
+		for _, value := range v.vector {
+			if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				return fmt.Errorf("vector has non-finite value: %v", value)
+			}
+			if value != 0 {
+				zero = false
+			}
+		}
+		if zero && info.SimilarityFunction == SimilarityFunctionCosine {
+			return fmt.Errorf("vector is all zeros, which is not allowed with %s similarity", SimilarityFunctionCosine)
+		}
+	}
+
+	if v.sparseVector != nil {
+		sv := v.sparseVector
+		if len(sv.Indices) != len(sv.Values) {
+			return fmt.Errorf("sparse vector has %d indices but %d values", len(sv.Indices), len(sv.Values))
+		}
+		for i, index := range sv.Indices {
+			if index < 0 {
+				return fmt.Errorf("sparse vector has negative index: %d", index)
+			}
+			if value := sv.Values[i]; math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				return fmt.Errorf("sparse vector has non-finite value: %v", value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package vector
+
+import (
+	"errors"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateVectors(t *testing.T) {
+	ix := &Index{
+		validate: true,
+		info:     &IndexInfo{Dimension: 2, SimilarityFunction: SimilarityFunctionCosine},
+	}
+
+	err := ix.validateUpserts(
+		Upsert{Id: "ok", Vector: []float32{0.6, 0.8}},
+		Upsert{Id: "dimension", Vector: []float32{1}},
+		Upsert{Id: "nan", Vector: []float32{float32(math.NaN()), 1}},
+		Upsert{Id: "zero", Vector: []float32{0, 0}},
+		Upsert{Id: "sparse", SparseVector: &SparseVector{Indices: []int32{1, 2}, Values: []float32{1}}},
+		Upsert{Vector: []float32{0.6, 0.8}},
+	)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []string{"dimension", "nan", "zero", "sparse"}, validationErr.Ids())
+	require.Equal(t, 5, len(validationErr.Vectors))
+	require.Equal(t, 5, validationErr.Vectors[4].Index)
+
+	require.NoError(t, ix.validateUpdate(Update{Id: "ok", Vector: []float32{1, 0}}))
+	require.NoError(t, ix.validateQuery(nil, &SparseVector{Indices: []int32{1}, Values: []float32{1}}))
+	require.Error(t, ix.validateQuery([]float32{1, 0, 0}, nil))
+
+	ix.validate = false
+	require.NoError(t, ix.validateQuery([]float32{1, 0, 0}, nil))
+}
+
+func TestUpsertWithValidation(t *testing.T) {
+	index := NewIndexWith(Options{
+		Url:      os.Getenv(UrlEnvProperty),
+		Token:    os.Getenv(TokenEnvProperty),
+		Validate: true,
+	})
+
+	err := index.UpsertMany([]Upsert{
+		{Id: randomString(), Vector: []float32{0.6, 0.8}},
+		{Id: randomString(), Vector: []float32{0.6, 0.8, 1}},
+	})
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, 1, len(validationErr.Vectors))
+	require.Equal(t, 1, validationErr.Vectors[0].Index)
+}
@@ -0,0 +1,179 @@
+package vecmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/upstash/vector-go"
+	"github.com/upstash/vector-go/internal/score"
+)
+
+// ValidateSparse returns an error if the sparse vector is malformed,
+// such as when it has different numbers of indices and values, or
+// has negative indices.
+func ValidateSparse(v *vector.SparseVector) error {
+	if v == nil {
+		return errors.New("sparse vector is nil")
+	}
+	if len(v.Indices) != len(v.Values) {
+		return fmt.Errorf("sparse vector has %d indices but %d values", len(v.Indices), len(v.Values))
+	}
+	for _, index := range v.Indices {
+		if index < 0 {
+			return fmt.Errorf("sparse vector has negative index: %d", index)
+		}
+	}
+	return nil
+}
+
+// SortSparse sorts the indices of the sparse vector in ascending
+// order in place, along with their values. A nil vector is left as is.
+// It panics if the vector has different numbers of indices and values.
+func SortSparse(v *vector.SparseVector) {
+	if v == nil {
+		return
+	}
+	checkSparse(v)
+	sort.Sort(sparseByIndex{v})
+}
+
+// Canonicalize returns a copy of the sparse vector with sorted indices, in
+// which the values of the duplicate indices are summed, and zero values are dropped.
+// It panics if the vector has different numbers of indices and values.
+func Canonicalize(v *vector.SparseVector) *vector.SparseVector {
+	checkSparse(v)
+	c := copySparse(v)
+	SortSparse(c)
+
+	n := 0
+	for i := 0; i < len(c.Indices); {
+		index := c.Indices[i]
+		var value float32
+		for ; i < len(c.Indices) && c.Indices[i] == index; i++ {
+			value += c.Values[i]
+		}
+		if value != 0 {
+			c.Indices[n] = index
+			c.Values[n] = value
+			n++
+		}
+	}
+	c.Indices = c.Indices[:n]
+	c.Values = c.Values[:n]
+	return c
+}
+
+// Prune returns a canonical copy of the sparse vector that only contains the
+// n values with the largest magnitudes. See Canonicalize.
+// If n is not positive, all the values are kept.
+// It panics if the vector has different numbers of indices and values.
+func Prune(v *vector.SparseVector, n int) *vector.SparseVector {
+	c := Canonicalize(v)
+	if n > 0 && len(c.Indices) > n {
+		sort.Stable(sparseByMagnitude{sparseByIndex{c}})
+		c.Indices = c.Indices[:n]
+		c.Values = c.Values[:n]
+	}
+	SortSparse(c)
+	return c
+}
+
+// SparseDot returns the inner product of the sparse vectors, which is
+// the score used for the sparse vectors in Upstash Vector. Duplicate
+// indices are treated as summed, and nil vectors as empty vectors.
+// It panics if any of the vectors has different numbers of indices and values.
+func SparseDot(a *vector.SparseVector, b *vector.SparseVector) float32 {
+	if a == nil || b == nil {
+		return 0
+	}
+	dot, err := score.Sparse(a.Indices, a.Values, b.Indices, b.Values)
+	if err != nil {
+		panic("vecmath: " + err.Error())
+	}
+	return dot
+}
+
+// SparseNorm returns the euclidean (L2) norm of the sparse vector.
+// It panics if the vector has different numbers of indices and values.
+func SparseNorm(v *vector.SparseVector) float32 {
+	return float32(math.Sqrt(float64(SparseDot(v, v))))
+}
+
+// NormalizeSparse returns a canonical copy of the sparse vector
+// scaled to unit L2 norm. See Canonicalize.
+// It panics if the vector has different numbers of indices and values.
+func NormalizeSparse(v *vector.SparseVector) *vector.SparseVector {
+	c := Canonicalize(v)
+	NormalizeInPlace(c.Values)
+	return c
+}
+
+// MeanSparse returns the element-wise average of the sparse vectors,
+// with sorted indices.
+func MeanSparse(vectors ...*vector.SparseVector) (*vector.SparseVector, error) {
+	if len(vectors) == 0 {
+		return nil, errors.New("at least one sparse vector is required")
+	}
+
+	sum := &vector.SparseVector{}
+	for _, v := range vectors {
+		if v == nil {
+			continue
+		}
+		if err := ValidateSparse(v); err != nil {
+			return nil, err
+		}
+		sum.Indices = append(sum.Indices, v.Indices...)
+		sum.Values = append(sum.Values, v.Values...)
+	}
+
+	mean := Canonicalize(sum)
+	n := float32(len(vectors))
+	for i := range mean.Values {
+		mean.Values[i] /= n
+	}
+	return mean, nil
+}
+
+func checkSparse(v *vector.SparseVector) {
+	if v != nil && len(v.Indices) != len(v.Values) {
+		panic(fmt.Sprintf("vecmath: sparse vector has %d indices but %d values", len(v.Indices), len(v.Values)))
+	}
+}
+
+func copySparse(v *vector.SparseVector) *vector.SparseVector {
+	if v == nil {
+		return &vector.SparseVector{}
+	}
+	return &vector.SparseVector{
+		Indices: append([]int32(nil), v.Indices...),
+		Values:  append([]float32(nil), v.Values...),
+	}
+}
+
+type sparseByIndex struct {
+	v *vector.SparseVector
+}
+
+func (s sparseByIndex) Len() int {
+	return len(s.v.Indices)
+}
+
+func (s sparseByIndex) Less(i, j int) bool {
+	return s.v.Indices[i] < s.v.Indices[j]
+}
+
+func (s sparseByIndex) Swap(i, j int) {
+	s.v.Indices[i], s.v.Indices[j] = s.v.Indices[j], s.v.Indices[i]
+	s.v.Values[i], s.v.Values[j] = s.v.Values[j], s.v.Values[i]
+}
+
+type sparseByMagnitude struct {
+	sparseByIndex
+}
+
+func (s sparseByMagnitude) Less(i, j int) bool {
+	return math.Abs(float64(s.v.Values[i])) > math.Abs(float64(s.v.Values[j]))
+}
@@ -0,0 +1,129 @@
+// Package vecmath implements math utilities for dense and sparse vectors.
+//
+// The scoring functions follow the same conventions with the query
+// scores of Upstash Vector, so that the scores calculated on the client
+// can be compared with the scores returned from the server. The
+// same kernels are used by the vector package, such as for QueryMMR.
+//
+// The functions that take dense vectors of different dimensions, or sparse
+// vectors with different numbers of indices and values, panic. ValidateSparse
+// can be used to check the sparse vectors of untrusted sources first.
+package vecmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/upstash/vector-go/internal/score"
+)
+
+// Dot returns the inner product of the vectors.
+// It panics if the vectors have different dimensions.
+func Dot(a []float32, b []float32) float32 {
+	return float32(dot(a, b))
+}
+
+// SquaredDistance returns the squared euclidean distance between the vectors.
+// It panics if the vectors have different dimensions.
+func SquaredDistance(a []float32, b []float32) float32 {
+	return float32(squaredDistance(a, b))
+}
+
+// Distance returns the euclidean distance between the vectors.
+// It panics if the vectors have different dimensions.
+func Distance(a []float32, b []float32) float32 {
+	return float32(math.Sqrt(squaredDistance(a, b)))
+}
+
+// Norm returns the euclidean (L2) norm of the vector.
+func Norm(a []float32) float32 {
+	return float32(math.Sqrt(dot(a, a)))
+}
+
+// Cosine returns the cosine of the angle between the vectors, which is
+// between -1 and 1. It returns 0 when any of the vectors is all zeros.
+// It panics if the vectors have different dimensions.
+func Cosine(a []float32, b []float32) float32 {
+	return float32(cosine(a, b))
+}
+
+// Normalize returns a copy of the vector scaled to unit L2 norm.
+// A vector of all zeros is returned as a copy of itself.
+func Normalize(a []float32) []float32 {
+	normalized := make([]float32, len(a))
+	copy(normalized, a)
+	NormalizeInPlace(normalized)
+	return normalized
+}
+
+// NormalizeInPlace scales the vector to unit L2 norm in place.
+// A vector of all zeros is left as it is.
+func NormalizeInPlace(a []float32) {
+	norm := math.Sqrt(dot(a, a))
+	if norm == 0 {
+		return
+	}
+	scale := 1 / norm
+	for i := range a {
+		a[i] = float32(float64(a[i]) * scale)
+	}
+}
+
+// Mean returns the element-wise average of the vectors.
+func Mean(vectors ...[]float32) ([]float32, error) {
+	if len(vectors) == 0 {
+		return nil, errors.New("at least one vector is required")
+	}
+
+	sum := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		if len(v) != len(sum) {
+			return nil, fmt.Errorf("vector dimensions do not match: %d != %d", len(v), len(sum))
+		}
+		for i, x := range v {
+			sum[i] += float64(x)
+		}
+	}
+
+	mean := make([]float32, len(sum))
+	n := float64(len(vectors))
+	for i, s := range sum {
+		mean[i] = float32(s / n)
+	}
+	return mean, nil
+}
+
+// Score returns the score of the dense vectors with the given similarity
+// function, as it would be returned from a query to an index with that
+// similarity function:
+//
+//   - COSINE: (1 + cosine(a, b)) / 2, or 0.5 when any of the vectors is all zeros
+//   - EUCLIDEAN: 1 / (1 + squared distance(a, b))
+//   - DOT_PRODUCT: (1 + dot(a, b)) / 2
+//
+// The similarity function of an index is available in IndexInfo.SimilarityFunction.
+func Score(similarityFunction string, a []float32, b []float32) (float32, error) {
+	return score.Dense(similarityFunction, a, b)
+}
+
+func checkDimensions(a []float32, b []float32) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("vecmath: vector dimensions do not match: %d != %d", len(a), len(b)))
+	}
+}
+
+func dot(a []float32, b []float32) float64 {
+	checkDimensions(a, b)
+	return score.Dot(a, b)
+}
+
+func squaredDistance(a []float32, b []float32) float64 {
+	checkDimensions(a, b)
+	return score.SquaredDistance(a, b)
+}
+
+func cosine(a []float32, b []float32) float64 {
+	checkDimensions(a, b)
+	return score.CosineSimilarity(a, b)
+}
@@ -0,0 +1,137 @@
+package vecmath
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go"
+)
+
+func TestDense(t *testing.T) {
+	a := []float32{1, 2, 3, 4, 5}
+	b := []float32{5, 4, 3, 2, 1}
+
+	require.Equal(t, float32(35), Dot(a, b))
+	require.Equal(t, float32(40), SquaredDistance(a, b))
+	require.InDelta(t, 6.3246, Distance(a, b), 1e-4)
+	require.InDelta(t, 7.4162, Norm(a), 1e-4)
+	require.InDelta(t, 35.0/55, Cosine(a, b), 1e-6)
+	require.Equal(t, float32(0), Cosine(a, make([]float32, 5)))
+
+	require.Panics(t, func() { Dot(a, b[:4]) })
+}
+
+func TestNormalize(t *testing.T) {
+	a := []float32{3, 4}
+	require.Equal(t, []float32{0.6, 0.8}, Normalize(a))
+	require.Equal(t, []float32{3, 4}, a)
+
+	zero := []float32{0, 0}
+	NormalizeInPlace(zero)
+	require.Equal(t, []float32{0, 0}, zero)
+}
+
+func TestMean(t *testing.T) {
+	mean, err := Mean([]float32{1, 2}, []float32{3, 6})
+	require.NoError(t, err)
+	require.Equal(t, []float32{2, 4}, mean)
+
+	_, err = Mean([]float32{1, 2}, []float32{3})
+	require.Error(t, err)
+
+	_, err = Mean()
+	require.Error(t, err)
+}
+
+func TestScore(t *testing.T) {
+	score, err := Score(vector.SimilarityFunctionCosine, []float32{1, 0}, []float32{-2, 0})
+	require.NoError(t, err)
+	require.Equal(t, float32(0), score)
+
+	score, err = Score(vector.SimilarityFunctionCosine, []float32{1, 0}, []float32{0, 0})
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), score)
+
+	score, err = Score(vector.SimilarityFunctionEuclidean, []float32{1, 0}, []float32{0, 1})
+	require.NoError(t, err)
+	require.InDelta(t, 1.0/3, score, 1e-6)
+
+	score, err = Score(vector.SimilarityFunctionDotProduct, []float32{0.6, 0.8}, []float32{0.8, 0.6})
+	require.NoError(t, err)
+	require.InDelta(t, 0.98, score, 1e-6)
+
+	_, err = Score(vector.SimilarityFunctionCosine, []float32{1, 0}, []float32{1})
+	require.Error(t, err)
+
+	_, err = Score("unknown", []float32{1}, []float32{1})
+	require.Error(t, err)
+}
+
+func TestSparse(t *testing.T) {
+	v := &vector.SparseVector{Indices: []int32{5, 1, 5, 3, 2}, Values: []float32{1, -4, 2, 0, 2}}
+
+	require.Equal(t, &vector.SparseVector{
+		Indices: []int32{1, 2, 5},
+		Values:  []float32{-4, 2, 3},
+	}, Canonicalize(v))
+	require.Equal(t, []int32{5, 1, 5, 3, 2}, v.Indices)
+
+	require.Equal(t, &vector.SparseVector{
+		Indices: []int32{1, 5},
+		Values:  []float32{-4, 3},
+	}, Prune(v, 2))
+
+	require.Equal(t, float32(29), SparseDot(v, v))
+	require.Equal(t, float32(0), SparseDot(v, nil))
+	require.InDelta(t, 5.3852, SparseNorm(v), 1e-4)
+	require.InDelta(t, 1, SparseNorm(NormalizeSparse(v)), 1e-6)
+
+	SortSparse(v)
+	require.Equal(t, []int32{1, 2, 3, 5, 5}, v.Indices)
+	require.Equal(t, float32(-4), v.Values[0])
+	require.NotPanics(t, func() { SortSparse(nil) })
+
+	malformed := &vector.SparseVector{Indices: []int32{1, 2}, Values: []float32{1}}
+	require.Panics(t, func() { SortSparse(malformed) })
+	require.Panics(t, func() { Canonicalize(malformed) })
+	require.Panics(t, func() { Prune(malformed, 1) })
+	require.Panics(t, func() { SparseDot(v, malformed) })
+	require.Panics(t, func() { SparseNorm(malformed) })
+	require.Panics(t, func() { NormalizeSparse(malformed) })
+
+	require.NoError(t, ValidateSparse(v))
+	require.Error(t, ValidateSparse(&vector.SparseVector{Indices: []int32{1}}))
+	require.Error(t, ValidateSparse(&vector.SparseVector{Indices: []int32{-1}, Values: []float32{1}}))
+
+	mean, err := MeanSparse(
+		&vector.SparseVector{Indices: []int32{1, 2}, Values: []float32{2, 4}},
+		&vector.SparseVector{Indices: []int32{2, 3}, Values: []float32{2, 6}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &vector.SparseVector{Indices: []int32{1, 2, 3}, Values: []float32{1, 3, 3}}, mean)
+}
+
+func BenchmarkDot(b *testing.B) {
+	x, y := randomVector(1536), randomVector(1536)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Dot(x, y)
+	}
+}
+
+func BenchmarkCosine(b *testing.B) {
+	x, y := randomVector(1536), randomVector(1536)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Cosine(x, y)
+	}
+}
+
+func randomVector(dimension int) []float32 {
+	v := make([]float32, dimension)
+	for i := range v {
+		v[i] = rand.Float32()
+	}
+	return v
+}
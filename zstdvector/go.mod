@@ -0,0 +1,19 @@
+module github.com/upstash/vector-go/zstdvector
+
+go 1.22
+
+// The client is required from the repository until the release
+// with the compression codecs is tagged.
+replace github.com/upstash/vector-go => ../
+
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/stretchr/testify v1.9.0
+	github.com/upstash/vector-go v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
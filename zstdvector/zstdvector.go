@@ -0,0 +1,55 @@
+// Package zstdvector compresses the requests and responses of the
+// Upstash Vector client with zstd.
+//
+//	index := vector.NewIndexWith(vector.Options{
+//		Url:   "<UPSTASH_VECTOR_REST_URL>",
+//		Token: "<UPSTASH_VECTOR_REST_TOKEN>",
+//		Compression: &vector.CompressionOptions{
+//			Codec: zstdvector.New(),
+//		},
+//	})
+//
+// It is a separate module, so that the client does not
+// depend on the zstd implementation unless it is used.
+package zstdvector
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/upstash/vector-go"
+)
+
+// ContentEncoding is the content encoding of the zstd codec.
+const ContentEncoding = "zstd"
+
+type codec struct{}
+
+// New returns the codec that compresses the request bodies and
+// decompresses the response bodies with zstd.
+func New() vector.Codec {
+	return codec{}
+}
+
+// zstd encoders are safe for concurrent use with EncodeAll, so it is shared.
+var encoder = sync.OnceValue(func() *zstd.Encoder {
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	return encoder
+})
+
+func (codec) ContentEncoding() string {
+	return ContentEncoding
+}
+
+func (codec) Compress(dst []byte, body []byte) ([]byte, error) {
+	return encoder().EncodeAll(body, dst), nil
+}
+
+func (codec) NewReader(body io.Reader) (io.ReadCloser, error) {
+	r, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return r.IOReadCloser(), nil
+}
@@ -0,0 +1,49 @@
+package zstdvector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/upstash/vector-go"
+)
+
+func TestCodec(t *testing.T) {
+	codec := New()
+	data := strings.Repeat("a", 1000)
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, ContentEncoding, r.Header.Get("Content-Encoding"))
+		require.Equal(t, "zstd, gzip", r.Header.Get("Accept-Encoding"))
+
+		decompressed, err := codec.NewReader(r.Body)
+		require.NoError(t, err)
+		defer decompressed.Close()
+		body, err = io.ReadAll(decompressed)
+		require.NoError(t, err)
+
+		response, err := codec.Compress(nil, []byte(`{"result":[{"id":"a","score":1,"data":"`+data+`"}]}`))
+		require.NoError(t, err)
+		w.Header().Set("Content-Encoding", ContentEncoding)
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	index := vector.NewIndexWith(vector.Options{
+		Url:   server.URL,
+		Token: "token",
+		Compression: &vector.CompressionOptions{
+			Codec:     codec,
+			Threshold: 1,
+		},
+	})
+
+	scores, err := index.QueryData(vector.QueryData{Data: data, TopK: 1, IncludeData: true})
+	require.NoError(t, err)
+	require.Equal(t, `{"data":"`+data+`","topK":1,"includeData":true}`, string(body))
+	require.Equal(t, []vector.VectorScore{{Id: "a", Score: 1, Data: data}}, scores)
+}